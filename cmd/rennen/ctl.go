@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thejawker/rennen/internal/control"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+const ctlUsage = `usage: ren ctl <command> [name] [flags]
+
+commands:
+  list                 the processes and commands
+  status               whether they're running
+  start <process>      start a process that isn't running
+  stop <process>       stop a process
+  restart <process>    restart a process
+  clear <process>      clear the output of a process
+  trigger <command>    run a command
+  tail <process>       print the output of a process, -f to follow it
+`
+
+// controlRunning talks to the ren that is running in the project over its
+// control socket
+func controlRunning(configPath string, args []string) error {
+	flags := flag.NewFlagSet("ctl", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	follow := flags.Bool("f", false, "keep printing new output, for tail")
+	lines := flags.Int("n", 100, "number of lines, for tail")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), ctlUsage+"\nflags:\n")
+		flags.PrintDefaults()
+	}
+
+	// allow the flags anywhere between the arguments
+	var positional []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(positional) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	method, params := positional[0], control.Params{Lines: *lines}
+	if len(positional) > 1 {
+		params.Name = positional[1]
+	}
+
+	client, err := control.Dial(control.SocketPath(filepath.Dir(configPath)))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	switch method {
+	case "list":
+		var entries []control.Entry
+		if err := client.Call(method, params, &entries); err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(entries)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t$ %s\n", e.Name, e.Kind, e.Command)
+		}
+		return w.Flush()
+	case "status":
+		var statuses []control.Status
+		if err := client.Call(method, params, &statuses); err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(statuses)
+		}
+		return printStatuses(statuses)
+	case "tail":
+		return tailRunning(client, params, *follow, *asJSON)
+	}
+
+	if params.Name == "" {
+		return fmt.Errorf("ren ctl %s needs the name of a process", method)
+	}
+
+	var status control.Status
+	if err := client.Call(method, params, &status); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(status)
+	}
+	return printStatuses([]control.Status{status})
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
+
+func printStatuses(statuses []control.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range statuses {
+		state := s.State
+		switch {
+		case s.StartedAt != nil:
+			state += " for " + utils.RelativeTime(*s.StartedAt)
+		case s.ExitCode != nil:
+			state += fmt.Sprintf(" with code %d", *s.ExitCode)
+		}
+		if s.Health != "" {
+			state += " (" + s.Health + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.Kind, state, utils.StripAnsi(s.LastLine))
+	}
+	return w.Flush()
+}
+
+// tailRunning prints the recent output of a process, and with follow keeps
+// asking for newer lines
+func tailRunning(client *control.Client, params control.Params, follow, asJSON bool) error {
+	if params.Name == "" {
+		return fmt.Errorf("ren ctl tail needs the name of a process")
+	}
+
+	for {
+		var lines []control.Line
+		if err := client.Call("tail", params, &lines); err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			if asJSON {
+				data, _ := json.Marshal(line)
+				fmt.Println(string(data))
+			} else {
+				fmt.Println(line.Text)
+			}
+			params.After = line.Seq
+		}
+
+		if !follow {
+			return nil
+		}
+
+		// everything that comes in from now on, however much it is
+		params.Lines = 10000
+		time.Sleep(250 * time.Millisecond)
+	}
+}
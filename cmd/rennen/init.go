@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+	"github.com/thejawker/rennen/internal/detect"
+	"github.com/thejawker/rennen/internal/wizard"
+)
+
+// detectedConfig looks for scripts, targets and services in the project and
+// lets the user pick which of them end up as processes and commands
+func detectedConfig(dir string) (map[string][]map[string]interface{}, error) {
+	cfg := map[string][]map[string]interface{}{
+		"processes": {},
+		"commands":  {},
+	}
+
+	entries := detect.Project(dir)
+	if len(entries) == 0 {
+		return cfg, nil
+	}
+
+	checklist := wizard.NewChecklist(entries)
+
+	// without a terminal there's nobody to ask, so go with the suggestions
+	if isTerminal(os.Stdin) {
+		if _, err := tea.NewProgram(checklist).Run(); err != nil {
+			return nil, fmt.Errorf("error running the checklist: %w", err)
+		}
+		if checklist.Cancelled {
+			return nil, fmt.Errorf("cancelled, nothing was written")
+		}
+	}
+
+	for _, item := range checklist.Items {
+		entry := map[string]interface{}{
+			"shortname":   item.Entry.Shortname,
+			"description": item.Entry.Description,
+			"command":     item.Entry.Command,
+		}
+
+		if item.Entry.Service != "" {
+			delete(entry, "command")
+			entry["type"] = "compose"
+			entry["service"] = item.Entry.Service
+		}
+
+		switch item.Choice {
+		case wizard.ChoiceProcess:
+			cfg["processes"] = append(cfg["processes"], entry)
+		case wizard.ChoiceCommand:
+			cfg["commands"] = append(cfg["commands"], entry)
+		}
+	}
+
+	return cfg, nil
+}
+
+func isTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/thejawker/rennen/internal/config"
+)
+
+// listConfig prints the processes and commands with all variables expanded
+func listConfig(configPath string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	printSection(w, "processes", cfg.Processes)
+	printSection(w, "commands", cfg.Commands)
+
+	return w.Flush()
+}
+
+func printSection(w *tabwriter.Writer, title string, procs []config.ProcessConfig) {
+	if len(procs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, proc := range procs {
+		fmt.Fprintf(w, "  %s\t$ %s\n", proc.Shortname, proc.Command)
+		if proc.Cwd != "" {
+			fmt.Fprintf(w, "  \t  cwd: %s\n", proc.Cwd)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/thejawker/rennen/internal/logging"
+	"github.com/thejawker/rennen/internal/watcher"
+)
+
+// showLogs prints the end of the log file of a process, and with -f keeps
+// printing whatever is written to it after that
+func showLogs(configPath string, args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ExitOnError)
+	follow := flags.Bool("f", false, "keep printing new output as it comes in")
+	lines := flags.Int("n", 100, "number of lines to print")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: ren logs <shortname> [-f] [-n lines]")
+		flags.PrintDefaults()
+	}
+
+	// allow the flags both before and after the shortname
+	flags.Parse(args)
+	shortname := flags.Arg(0)
+	if flags.NArg() > 1 {
+		flags.Parse(flags.Args()[1:])
+	}
+	if shortname == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	var path string
+	found := false
+	for _, proc := range cfg.Processes {
+		if proc.Shortname == shortname {
+			found = true
+			if proc.Log != nil {
+				path = proc.Log.Path
+			}
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("there is no process called %q", shortname)
+	}
+	if path == "" {
+		return fmt.Errorf("%s doesn't write its output anywhere, set log_dir or log_file in the config", shortname)
+	}
+
+	if err := printTail(path, *lines); err != nil {
+		return err
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	return followLog(path)
+}
+
+// printTail prints the last n lines of the log, reaching back into the most
+// recent rotated file when the current one is still short
+func printTail(path string, n int) error {
+	var tail []string
+	for _, file := range []string{logging.RotatedPath(path, 1), path} {
+		lines, err := readLines(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		tail = append(tail, lines...)
+		if len(tail) > n {
+			tail = tail[len(tail)-n:]
+		}
+	}
+
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
+
+// followLog prints everything that is appended to the log until interrupted,
+// starting over when the file is rotated
+func followLog(path string) error {
+	offset := int64(0)
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	w := watcher.WatchFile(path, 250*time.Millisecond)
+	defer w.Close()
+
+	for range w.Changes() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		// a smaller file means it was rotated and this is a new one
+		if info.Size() < offset {
+			offset = 0
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		if _, err := file.Seek(offset, io.SeekStart); err == nil {
+			n, _ := io.Copy(os.Stdout, file)
+			offset += n
+		}
+		file.Close()
+	}
+
+	return nil
+}
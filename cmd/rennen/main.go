@@ -6,23 +6,34 @@ import (
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/control"
 	"github.com/thejawker/rennen/internal/logging"
+	"github.com/thejawker/rennen/internal/metrics"
 	"github.com/thejawker/rennen/internal/model"
 	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/session"
+	"github.com/thejawker/rennen/internal/terminal"
+	"github.com/thejawker/rennen/internal/web"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 )
 
 func main() {
 	configPath := flag.String("config", "./ren.json", "path to config file")
 	showVersion := flag.Bool("version", false, "show version information")
 	verbosityLevel := flag.String("logging", "none", "logs to ./ren.log verbosity level: none, all")
+	httpAddr := flag.String("http", "", "serve the web dashboard and API on this address, like :7777 for 127.0.0.1:7777")
+	metricsAddr := flag.String("metrics", "", "serve prometheus metrics on this address, like :9090 for 127.0.0.1:9090")
+	noRecord := flag.Bool("no-record", false, "don't record this session to .ren/sessions")
 
 	flag.Parse()
 
 	// handle positional arguments
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
+	if flag.NArg() > 0 {
+		switch flag.Arg(0) {
 		case "init":
 			err := generateDefaultConfig(*configPath)
 			if err != nil {
@@ -31,6 +42,30 @@ func main() {
 			}
 			fmt.Println("okay, just generated that at", *configPath)
 			return
+		case "logs":
+			if err := showLogs(*configPath, flag.Args()[1:]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		case "ctl":
+			if err := controlRunning(*configPath, flag.Args()[1:]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		case "replay":
+			if err := replaySession(*configPath, flag.Args()[1:]); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		case "list":
+			if err := listConfig(*configPath); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
 		}
 	}
 
@@ -46,7 +81,7 @@ func main() {
 	// Defer and handle the error
 	defer func() {
 		if err := closeLogger(); err != nil {
-			fmt.Printf("error closing log file: %w", err)
+			fmt.Printf("error closing log file: %v", err)
 		}
 	}()
 
@@ -72,18 +107,76 @@ func main() {
 
 	// Create and initialize the model
 	m := model.New(processes, commands)
+	m.ConfigPath = *configPath
+	m.SetViews(cfg.Views)
+	m.SetKeys(cfg.Keys)
+	m.SetAppearance(cfg)
+	m.CPUWarning, m.MemoryWarning = cfg.CPUWarning, int64(cfg.MemoryWarning)
+
+	// record the session so it can be replayed after a crash
+	var recorder *session.Recorder
+	if !*noRecord {
+		recorder, err = session.Start(session.Root(cfg.Dir), *configPath)
+		if err != nil {
+			log.Printf("Error recording session: %v\n", err)
+		} else {
+			m.SetRecorder(recorder)
+			defer recorder.Close()
+		}
+	}
+
+	// let scripts control this ren through .ren/ren.sock
+	server, err := control.Listen(control.SocketPath(cfg.Dir), m.HandleControl)
+	if err != nil {
+		log.Printf("Error starting control socket: %v\n", err)
+	} else {
+		defer server.Close()
+	}
+
+	if *httpAddr != "" {
+		web, err := web.Listen(*httpAddr, m.HandleControl)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer web.Close()
+	}
+
+	if *metricsAddr != "" {
+		metrics, err := metrics.Listen(*metricsAddr, m.Snapshot)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer metrics.Close()
+	}
 
 	// Create and start the Bubble Tea program
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(terminal.Output))
+
+	// the deferred calls don't run when the terminal goes away or ren gets
+	// killed, so the recording is flushed and ren asked to quit instead
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP, syscall.SIGTERM)
+		<-signals
+		if recorder != nil {
+			recorder.Flush()
+		}
+		p.Quit()
+	}()
 
 	if _, err := p.Run(); err != nil {
+		if recorder != nil {
+			recorder.Close()
+		}
 		log.Fatalf("Error running program: %v", err)
 	}
 
 	fmt.Println("\nwoah that was cool i guess, all is stopped now though")
 }
 
-func generateDefaultConfig(path string) interface{} {
+func generateDefaultConfig(path string) error {
 	// if already exists, panic and exit
 	if _, err := os.Stat(path); err == nil {
 		log.Fatalf("config file already exists at %s", path)
@@ -91,15 +184,20 @@ func generateDefaultConfig(path string) interface{} {
 		log.Fatalf("error checking if config file exists: %v", err)
 	}
 
-	defaultConfig := map[string]interface{}{
-		// list of objects
-		"processes": []map[string]interface{}{
+	defaultConfig, err := detectedConfig(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	// nothing useful found, so start off with an example
+	if len(defaultConfig["processes"]) == 0 {
+		defaultConfig["processes"] = []map[string]interface{}{
 			{
 				"shortname":   "test",
 				"description": "a sample process",
 				"command":     "echo 'hello world'",
 			},
-		},
+		}
 	}
 
 	file, err := os.Create(path)
@@ -115,6 +213,7 @@ func generateDefaultConfig(path string) interface{} {
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
 
 	return encoder.Encode(defaultConfig)
 }
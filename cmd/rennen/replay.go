@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/model"
+	"github.com/thejawker/rennen/internal/session"
+	"github.com/thejawker/rennen/internal/terminal"
+)
+
+// replaySession opens a recorded session in the TUI, read-only. without a
+// session id it opens the most recent one.
+func replaySession(configPath string, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	list := flags.Bool("l", false, "list the recorded sessions")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: ren replay [-l] [session]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	root := session.Root(filepath.Dir(configPath))
+	ids, err := session.List(root)
+	if err != nil {
+		return fmt.Errorf("failed to read sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("no sessions recorded in %s yet", root)
+	}
+
+	if *list {
+		return listSessions(root, ids)
+	}
+
+	id := ids[len(ids)-1]
+	if flags.NArg() > 0 {
+		id = flags.Arg(0)
+	}
+
+	dir := filepath.Join(root, id)
+	manifest, err := session.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	m, err := model.NewReplay(dir, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(terminal.Output))
+	_, err = p.Run()
+
+	return err
+}
+
+func listSessions(root string, ids []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, id := range ids {
+		manifest, err := session.Open(filepath.Join(root, id))
+		if err != nil {
+			fmt.Fprintf(w, "%s\t%v\n", id, err)
+			continue
+		}
+
+		ended := "didn't shut down cleanly"
+		if manifest.EndedAt != nil {
+			ended = "ran for " + manifest.EndedAt.Sub(manifest.StartedAt).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d processes\t%s\n", id, manifest.StartedAt.Format("Mon 2 Jan 15:04:05"), len(manifest.Processes), ended)
+	}
+
+	return w.Flush()
+}
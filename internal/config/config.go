@@ -4,19 +4,117 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/thejawker/rennen/internal/keys"
+	"github.com/thejawker/rennen/internal/theme"
 )
 
 // Config represents the structure of our configuration file
 type Config struct {
-	Processes []ProcessConfig `json:"processes"`
-	Commands  []ProcessConfig `json:"commands"`
+	Vars      map[string]string `json:"vars"`
+	Processes []ProcessConfig   `json:"processes"`
+	Commands  []ProcessConfig   `json:"commands"`
+	Views     []ViewConfig      `json:"views"`
+
+	// LogDir is where the output of every process is written to, one file
+	// per process, unless a process sets its own log_file
+	LogDir      string `json:"log_dir"`
+	LogMaxSize  Size   `json:"log_max_size"`
+	LogMaxFiles int    `json:"log_max_files"`
+	LogANSI     bool   `json:"log_ansi"`
+
+	// CPUWarning and MemoryWarning highlight processes in the overview that
+	// use more than this percentage of a core or this much memory
+	CPUWarning    float64 `json:"cpu_warning"`
+	MemoryWarning Size    `json:"memory_warning"`
+
+	// Keys overrides key bindings by name, like "restart": ["R"]
+	Keys map[string][]string `json:"keys"`
+
+	// Theme is the name of a built-in theme or one of Themes, which are
+	// defined on top of a built-in one
+	Theme  string                      `json:"theme"`
+	Themes map[string]theme.Definition `json:"themes"`
+	// Layout is "default", "compact" to leave out the borders, or "sidebar"
+	// to list the tabs down the left side
+	Layout string `json:"layout"`
+
+	// Dir is the absolute directory the config file was loaded from
+	Dir string `json:"-"`
 }
 
 // ProcessConfig represents the configuration for a single process
 type ProcessConfig struct {
-	Shortname   string `json:"shortname"`
-	Command     string `json:"command"`
-	Description string `json:"description"`
+	Shortname   string            `json:"shortname"`
+	Command     string            `json:"command"`
+	Description string            `json:"description"`
+	Type        string            `json:"type"`
+	Service     string            `json:"service"`
+	Cwd         string            `json:"cwd"`
+	Env         map[string]string `json:"env"`
+	Watch       *WatchConfig      `json:"watch"`
+	LogFile     string            `json:"log_file"`
+	Triggers    []TriggerConfig   `json:"triggers"`
+	// Format is how the output is shown: "auto" pretty-prints lines that
+	// are JSON objects, "json" also the ones with something in front of the
+	// object, and "text" shows every line as it is
+	Format string `json:"format"`
+	// Notify is when the tab shows there's unseen output: "any" line, only
+	// "errors", or "never"
+	Notify string `json:"notify"`
+
+	// Log is where the output of the process is written to, resolved from
+	// log_dir and log_file. it's nil when the output isn't kept.
+	Log *LogConfig `json:"-"`
+}
+
+// WatchConfig configures restarting a process when files change
+type WatchConfig struct {
+	Paths    []string `json:"paths"`
+	Include  []string `json:"include"`
+	Exclude  []string `json:"exclude"`
+	Debounce Duration `json:"debounce"`
+
+	// Dir is the directory the paths are relative to, which is the cwd of
+	// the process or otherwise the directory of the config file
+	Dir string `json:"-"`
+}
+
+// TriggerConfig acts on lines of output that match a pattern
+type TriggerConfig struct {
+	Pattern  string   `json:"pattern"`
+	Severity string   `json:"severity"`
+	Actions  []string `json:"actions"`
+	// Run is a command that's run for every match
+	Run string `json:"run"`
+
+	Regexp *regexp.Regexp `json:"-"`
+}
+
+// Severities are the severities a trigger can have, from low to high
+var Severities = []string{"info", "warning", "error"}
+
+// TriggerActions are the things a trigger can do besides running a command
+var TriggerActions = []string{"mark", "bell", "notify", "restart"}
+
+// LogConfig says where and how the output of a process is written to disk
+type LogConfig struct {
+	Path     string
+	MaxSize  int64
+	MaxFiles int
+	ANSI     bool
+}
+
+// ViewConfig is a named layout that shows several processes side by side
+type ViewConfig struct {
+	Name      string   `json:"name"`
+	Processes []string `json:"processes"`
+	Columns   int      `json:"columns"`
 }
 
 // Load reads and parses the configuration file at the given path
@@ -37,6 +135,23 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+
+	if err := interpolate(&cfg, dir); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	cfg.Dir = dir
+	resolveCompose(&cfg)
+	resolveWatches(&cfg)
+	resolveLogs(&cfg)
+	resolveWarnings(&cfg)
+	resolveTriggers(&cfg)
+	resolveNotify(&cfg)
+
 	return &cfg, nil
 }
 
@@ -50,10 +165,288 @@ func validate(cfg *Config) error {
 		if proc.Shortname == "" {
 			return fmt.Errorf("process %d is missing a shortname", i+1)
 		}
-		if proc.Command == "" {
+		if err := validateType(proc); err != nil {
+			return fmt.Errorf("process %d (%s) %w", i+1, proc.Shortname, err)
+		}
+		if proc.Type != "compose" && proc.Command == "" {
 			return fmt.Errorf("process %d (%s) is missing a command", i+1, proc.Shortname)
 		}
+		if err := validateWatch(proc.Watch); err != nil {
+			return fmt.Errorf("process %d (%s) has an invalid watch: %w", i+1, proc.Shortname, err)
+		}
+		switch proc.Format {
+		case "", "auto", "json", "text":
+		default:
+			return fmt.Errorf("process %d (%s) has an unknown format %q, use auto, json or text", i+1, proc.Shortname, proc.Format)
+		}
+		switch proc.Notify {
+		case "", "any", "errors", "never":
+		default:
+			return fmt.Errorf("process %d (%s) has an unknown notify %q, use any, errors or never", i+1, proc.Shortname, proc.Notify)
+		}
+		for j, trigger := range proc.Triggers {
+			if err := validateTrigger(trigger); err != nil {
+				return fmt.Errorf("process %d (%s) has an invalid trigger %d: %w", i+1, proc.Shortname, j+1, err)
+			}
+		}
+	}
+
+	for i, cmd := range cfg.Commands {
+		if err := validateType(cmd); err != nil {
+			return fmt.Errorf("command %d (%s) %w", i+1, cmd.Shortname, err)
+		}
+	}
+
+	if cfg.LogMaxSize < 0 {
+		return fmt.Errorf("log_max_size can't be negative")
+	}
+
+	if cfg.LogMaxFiles < 0 {
+		return fmt.Errorf("log_max_files can't be negative")
+	}
+
+	if cfg.CPUWarning < 0 {
+		return fmt.Errorf("cpu_warning can't be negative")
+	}
+
+	if cfg.MemoryWarning < 0 {
+		return fmt.Errorf("memory_warning can't be negative")
+	}
+
+	defaults := keys.Default()
+	if err := defaults.Apply(cfg.Keys); err != nil {
+		return fmt.Errorf("keys: %w", err)
+	}
+
+	if _, err := theme.Resolve(cfg.Theme, cfg.Themes); err != nil {
+		return err
+	}
+	for name := range cfg.Themes {
+		if _, err := theme.Resolve(name, cfg.Themes); err != nil {
+			return err
+		}
+	}
+
+	switch cfg.Layout {
+	case "", "default", "compact", "sidebar":
+	default:
+		return fmt.Errorf("unknown layout %q, use default, compact or sidebar", cfg.Layout)
+	}
+
+	for i, view := range cfg.Views {
+		if err := validateView(cfg, view); err != nil {
+			return fmt.Errorf("view %d (%s) %w", i+1, view.Name, err)
+		}
 	}
 
 	return nil
 }
+
+func validateView(cfg *Config, view ViewConfig) error {
+	if view.Name == "" {
+		return fmt.Errorf("is missing a name")
+	}
+
+	if len(view.Processes) == 0 {
+		return fmt.Errorf("has no processes")
+	}
+
+	if view.Columns < 0 {
+		return fmt.Errorf("can't have negative columns")
+	}
+
+	for _, name := range view.Processes {
+		found := false
+		for _, proc := range cfg.Processes {
+			if proc.Shortname == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("references unknown process %q", name)
+		}
+	}
+
+	return nil
+}
+
+func validateTrigger(trigger TriggerConfig) error {
+	if trigger.Pattern == "" {
+		return fmt.Errorf("is missing a pattern")
+	}
+
+	if _, err := regexp.Compile(trigger.Pattern); err != nil {
+		return fmt.Errorf("bad pattern %q: %w", trigger.Pattern, err)
+	}
+
+	if trigger.Severity != "" && !slices.Contains(Severities, trigger.Severity) {
+		return fmt.Errorf("has an unknown severity %q", trigger.Severity)
+	}
+
+	for _, action := range trigger.Actions {
+		if !slices.Contains(TriggerActions, action) {
+			return fmt.Errorf("has an unknown action %q", action)
+		}
+	}
+
+	return nil
+}
+
+// validateType checks the type of a process or command and what that type
+// needs
+func validateType(proc ProcessConfig) error {
+	switch proc.Type {
+	case "", "shell":
+	case "compose":
+		if proc.Service == "" {
+			return fmt.Errorf("is a compose process but is missing a service")
+		}
+	default:
+		return fmt.Errorf("has an unknown type %q", proc.Type)
+	}
+	return nil
+}
+
+func validateWatch(watch *WatchConfig) error {
+	if watch == nil {
+		return nil
+	}
+
+	for _, patterns := range [][]string{watch.Include, watch.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("bad pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	if watch.Debounce < 0 {
+		return fmt.Errorf("debounce can't be negative")
+	}
+
+	return nil
+}
+
+// resolveCompose fills in the command compose processes and commands run,
+// so that it shows up like any other command
+func resolveCompose(cfg *Config) {
+	for _, procs := range [][]ProcessConfig{cfg.Processes, cfg.Commands} {
+		for i := range procs {
+			proc := &procs[i]
+			if proc.Type == "compose" && proc.Command == "" {
+				proc.Command = "docker compose up " + proc.Service
+			}
+		}
+	}
+}
+
+// resolveWatches fills in the defaults of the watch configs and makes their
+// paths absolute
+func resolveWatches(cfg *Config) {
+	for i := range cfg.Processes {
+		watch := cfg.Processes[i].Watch
+		if watch == nil {
+			continue
+		}
+
+		watch.Dir = cfg.Processes[i].Cwd
+		if watch.Dir == "" {
+			watch.Dir = cfg.Dir
+		}
+
+		if len(watch.Paths) == 0 {
+			watch.Paths = []string{"."}
+		}
+
+		for j, p := range watch.Paths {
+			if !filepath.IsAbs(p) {
+				watch.Paths[j] = filepath.Join(watch.Dir, p)
+			}
+		}
+
+		if watch.Debounce == 0 {
+			watch.Debounce = Duration(300 * time.Millisecond)
+		}
+	}
+}
+
+// resolveLogs works out which file the output of each process goes to. a
+// process's own log_file wins over the shared log_dir.
+func resolveLogs(cfg *Config) {
+	maxSize := int64(cfg.LogMaxSize)
+	if maxSize == 0 {
+		maxSize = 10 * MB
+	}
+
+	maxFiles := cfg.LogMaxFiles
+	if maxFiles == 0 {
+		maxFiles = 5
+	}
+
+	for i := range cfg.Processes {
+		proc := &cfg.Processes[i]
+
+		path := proc.LogFile
+		if path == "" && cfg.LogDir != "" {
+			path = filepath.Join(cfg.LogDir, proc.Shortname+".log")
+		}
+		if path == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cfg.Dir, path)
+		}
+
+		proc.Log = &LogConfig{
+			Path:     path,
+			MaxSize:  maxSize,
+			MaxFiles: maxFiles,
+			ANSI:     cfg.LogANSI,
+		}
+	}
+}
+
+// resolveTriggers compiles the patterns of the triggers and fills in their
+// defaults, which is marking the tab as an error
+func resolveTriggers(cfg *Config) {
+	for i := range cfg.Processes {
+		for j := range cfg.Processes[i].Triggers {
+			trigger := &cfg.Processes[i].Triggers[j]
+			trigger.Regexp = regexp.MustCompile(trigger.Pattern)
+
+			if trigger.Severity == "" {
+				trigger.Severity = "error"
+			}
+
+			if len(trigger.Actions) == 0 && trigger.Run == "" {
+				trigger.Actions = []string{"mark"}
+			}
+		}
+	}
+}
+
+// resolveNotify makes processes notify about any output and detect JSON
+// unless they say otherwise
+func resolveNotify(cfg *Config) {
+	for i := range cfg.Processes {
+		if cfg.Processes[i].Notify == "" {
+			cfg.Processes[i].Notify = "any"
+		}
+		if cfg.Processes[i].Format == "" {
+			cfg.Processes[i].Format = "auto"
+		}
+	}
+}
+
+// resolveWarnings fills in the usage warnings that aren't configured
+func resolveWarnings(cfg *Config) {
+	if cfg.CPUWarning == 0 {
+		cfg.CPUWarning = 90
+	}
+
+	if cfg.MemoryWarning == 0 {
+		cfg.MemoryWarning = 1 * GB
+	}
+}
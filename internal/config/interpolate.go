@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// resolver expands ${...} references for a single field of the config
+type resolver struct {
+	vars      map[string]string
+	resolved  map[string]string
+	resolving map[string]bool
+	configDir string
+	process   *ProcessConfig
+	// shell is set while expanding a command, where bare names like ${f}
+	// are left for the shell to expand
+	shell bool
+}
+
+// interpolate expands all ${...} references in the vars, commands, cwd, env, log and trigger values.
+// supported references are ${vars.name}, ${env.NAME}, ${config_dir} and
+// ${process.shortname}, each of them optionally with a default like ${PORT:-3000}.
+// a bare ${NAME} is an environment variable, except in commands where it is
+// left for the shell. a literal "${" can be written as "$${".
+func interpolate(cfg *Config, configDir string) error {
+	r := &resolver{
+		vars:      cfg.Vars,
+		resolved:  make(map[string]string),
+		resolving: make(map[string]bool),
+		configDir: configDir,
+	}
+
+	// resolve the vars up front so errors point at the var that is broken
+	names := make([]string, 0, len(cfg.Vars))
+	for name := range cfg.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, err := r.variable(name)
+		if err != nil {
+			return fmt.Errorf("vars.%s: %w", name, err)
+		}
+		cfg.Vars[name] = value
+	}
+
+	logDir, err := r.expand(cfg.LogDir)
+	if err != nil {
+		return fmt.Errorf("log_dir: %w", err)
+	}
+	cfg.LogDir = logDir
+
+	if err := r.processes("processes", cfg.Processes); err != nil {
+		return err
+	}
+
+	return r.processes("commands", cfg.Commands)
+}
+
+func (r *resolver) processes(section string, procs []ProcessConfig) error {
+	for i := range procs {
+		proc := &procs[i]
+		r.process = proc
+		field := fmt.Sprintf("%s[%d] (%s)", section, i, proc.Shortname)
+
+		command, err := r.expandCommand(proc.Command)
+		if err != nil {
+			return fmt.Errorf("%s.command: %w", field, err)
+		}
+
+		cwd, err := r.expand(proc.Cwd)
+		if err != nil {
+			return fmt.Errorf("%s.cwd: %w", field, err)
+		}
+		if cwd != "" && !filepath.IsAbs(cwd) {
+			cwd = filepath.Join(r.configDir, cwd)
+		}
+
+		logFile, err := r.expand(proc.LogFile)
+		if err != nil {
+			return fmt.Errorf("%s.log_file: %w", field, err)
+		}
+
+		env := make(map[string]string, len(proc.Env))
+		for key, value := range proc.Env {
+			expanded, err := r.expand(value)
+			if err != nil {
+				return fmt.Errorf("%s.env.%s: %w", field, key, err)
+			}
+			env[key] = expanded
+		}
+
+		for j := range proc.Triggers {
+			run, err := r.expandCommand(proc.Triggers[j].Run)
+			if err != nil {
+				return fmt.Errorf("%s.triggers[%d].run: %w", field, j, err)
+			}
+			proc.Triggers[j].Run = run
+		}
+
+		proc.Command = command
+		proc.Cwd = cwd
+		proc.LogFile = logFile
+		if proc.Env != nil {
+			proc.Env = env
+		}
+	}
+
+	r.process = nil
+
+	return nil
+}
+
+// expandCommand expands a command, leaving bare names to the shell
+func (r *resolver) expandCommand(s string) (string, error) {
+	r.shell = true
+	defer func() { r.shell = false }()
+	return r.expand(s)
+}
+
+// expand replaces every reference in s with its value
+func (r *resolver) expand(s string) (string, error) {
+	var out strings.Builder
+
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+
+		// $${ is an escaped ${ and stays as is
+		if start > 0 && s[start-1] == '$' {
+			out.WriteString(s[:start-1])
+			out.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference %q", s[start:])
+		}
+		end += start
+
+		// names outside of the namespaces belong to the shell, like the
+		// loop variable in for f in *; do echo ${f}; done
+		if r.shell && !namespaced(s[start+2:end]) {
+			out.WriteString(s[:end+1])
+			s = s[end+1:]
+			continue
+		}
+
+		value, err := r.lookup(s[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+
+		out.WriteString(s[:start])
+		out.WriteString(value)
+		s = s[end+1:]
+	}
+}
+
+// namespaced reports whether the inside of a ${...} reference is one ren
+// resolves in a command rather than one for the shell
+func namespaced(ref string) bool {
+	name, _, _ := strings.Cut(ref, ":-")
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "config_dir" {
+		return true
+	}
+	for _, prefix := range []string{"vars.", "env.", "process."} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup resolves the inside of a single ${...} reference
+func (r *resolver) lookup(ref string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(ref, ":-")
+	name = strings.TrimSpace(name)
+
+	value, ok, err := r.value(name)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok || value == "" {
+		if hasFallback {
+			return fallback, nil
+		}
+		if !ok {
+			return "", fmt.Errorf("undefined reference ${%s}", name)
+		}
+	}
+
+	return value, nil
+}
+
+func (r *resolver) value(name string) (string, bool, error) {
+	switch {
+	case name == "config_dir":
+		return r.configDir, true, nil
+	case strings.HasPrefix(name, "vars."):
+		key := strings.TrimPrefix(name, "vars.")
+		if _, ok := r.vars[key]; !ok {
+			return "", false, nil
+		}
+		value, err := r.variable(key)
+		return value, err == nil, err
+	case strings.HasPrefix(name, "env."):
+		value, ok := os.LookupEnv(strings.TrimPrefix(name, "env."))
+		return value, ok, nil
+	case strings.HasPrefix(name, "process."):
+		if r.process == nil {
+			return "", false, fmt.Errorf("${%s} can only be used inside a process or command", name)
+		}
+		switch strings.TrimPrefix(name, "process.") {
+		case "shortname":
+			return r.process.Shortname, true, nil
+		case "description":
+			return r.process.Description, true, nil
+		}
+		return "", false, nil
+	case name == "":
+		return "", false, fmt.Errorf("empty reference ${}")
+	}
+
+	value, ok := os.LookupEnv(name)
+	return value, ok, nil
+}
+
+// variable resolves a var, which may itself reference other vars
+func (r *resolver) variable(name string) (string, error) {
+	if value, ok := r.resolved[name]; ok {
+		return value, nil
+	}
+
+	if r.resolving[name] {
+		return "", fmt.Errorf("vars.%s references itself", name)
+	}
+
+	r.resolving[name] = true
+	defer delete(r.resolving, name)
+
+	// vars are shared by every process, so they can't depend on one, and
+	// they are the same whether a command uses them or not
+	current, shell := r.process, r.shell
+	r.process, r.shell = nil, false
+	value, err := r.expand(r.vars[name])
+	r.process, r.shell = current, shell
+	if err != nil {
+		return "", err
+	}
+
+	r.resolved[name] = value
+
+	return value, nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	KB = 1024
+	MB = 1024 * KB
+	GB = 1024 * MB
+)
+
+// Size is a number of bytes that can be written in the config as either a
+// string like "512KB" or "10MB", or as a plain number of bytes
+type Size int64
+
+func (s *Size) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case float64:
+		*s = Size(v)
+	case string:
+		parsed, err := parseSize(v)
+		if err != nil {
+			return err
+		}
+		*s = parsed
+	default:
+		return fmt.Errorf("invalid size %s", string(data))
+	}
+
+	return nil
+}
+
+func parseSize(v string) (Size, error) {
+	text := strings.ToUpper(strings.TrimSpace(v))
+
+	unit := int64(1)
+	for _, suffix := range []struct {
+		name string
+		size int64
+	}{{"GB", GB}, {"MB", MB}, {"KB", KB}, {"G", GB}, {"M", MB}, {"K", KB}, {"B", 1}} {
+		if strings.HasSuffix(text, suffix.name) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, suffix.name))
+			unit = suffix.size
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", v)
+	}
+
+	return Size(number * float64(unit)), nil
+}
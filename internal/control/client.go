@@ -0,0 +1,70 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Client calls the methods of a running ren over its socket
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	id      int
+}
+
+// Dial connects to the ren listening on the socket at path
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("ren doesn't seem to be running here (%w)", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	return &Client{conn: conn, scanner: scanner}, nil
+}
+
+// Call calls a method and decodes its result into result, unless it's nil
+func (c *Client) Call(method string, params Params, result interface{}) error {
+	c.id++
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req := Request{Version: version, ID: c.id, Method: method, Params: data}
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return fmt.Errorf("ren closed the connection")
+	}
+
+	var response Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if response.Error != nil {
+		return response.Error
+	}
+
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
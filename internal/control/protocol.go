@@ -0,0 +1,98 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"time"
+)
+
+// the protocol is JSON-RPC 2.0 with one message per line
+
+const version = "2.0"
+
+// error codes from the JSON-RPC spec, and one for everything that went
+// wrong while doing what was asked
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeFailed         = -32000
+)
+
+var (
+	// ErrMethodNotFound is returned by handlers for methods they don't know
+	ErrMethodNotFound = errors.New("method not found")
+	// ErrInvalidParams is returned by handlers when the params don't make sense
+	ErrInvalidParams = errors.New("invalid params")
+)
+
+// Request is a call to a method of the running ren
+type Request struct {
+	Version string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is the answer to a request, with either a result or an error
+type Response struct {
+	Version string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is an error returned by the running ren
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Params are the params of all methods, each method uses the ones it needs
+type Params struct {
+	// Name is the process or command to act on
+	Name string `json:"name,omitempty"`
+	// Lines is the number of lines tail returns
+	Lines int `json:"lines,omitempty"`
+	// After makes tail return only lines newer than this sequence number
+	After uint64 `json:"after,omitempty"`
+}
+
+// Entry is a process or command as returned by list
+type Entry struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+}
+
+// Status is the state of a process or command as returned by status
+type Status struct {
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"`
+	Command   string     `json:"command"`
+	State     string     `json:"state"`
+	Pid       int        `json:"pid,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ExitCode  *int       `json:"exit_code,omitempty"`
+	Health    string     `json:"health,omitempty"`
+	LastLine  string     `json:"last_line,omitempty"`
+}
+
+// Line is a line of output as returned by tail
+type Line struct {
+	Seq    uint64    `json:"seq"`
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Text   string    `json:"text"`
+}
+
+// SocketPath is where the socket of the ren running in a project lives
+func SocketPath(configDir string) string {
+	return filepath.Join(configDir, ".ren", "ren.sock")
+}
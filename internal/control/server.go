@@ -0,0 +1,131 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Handler answers a call to a method. the result is sent back as JSON.
+type Handler func(method string, params Params) (interface{}, error)
+
+// Server listens on a Unix socket and passes the requests to a handler
+type Server struct {
+	path     string
+	listener net.Listener
+	handler  Handler
+	wg       sync.WaitGroup
+}
+
+// Listen starts serving on the socket at path. a socket left behind by a ren
+// that crashed is replaced, but one that another ren still listens on isn't.
+func Listen(path string, handler Handler) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another ren is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	s := &Server{path: path, listener: listener, handler: handler}
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Error accepting control connection: %v\n", err)
+			}
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+// handle answers the requests on a connection one by one until it's closed
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		response := s.respond(scanner.Bytes())
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) respond(data []byte) Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return Response{Version: version, Error: &Error{Code: codeParseError, Message: err.Error()}}
+	}
+
+	response := Response{Version: version, ID: req.ID}
+
+	var params Params
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			response.Error = &Error{Code: codeInvalidParams, Message: err.Error()}
+			return response
+		}
+	}
+
+	result, err := s.handler(req.Method, params)
+	if err != nil {
+		code := codeFailed
+		switch {
+		case errors.Is(err, ErrMethodNotFound):
+			code = codeMethodNotFound
+		case errors.Is(err, ErrInvalidParams):
+			code = codeInvalidParams
+		}
+		response.Error = &Error{Code: code, Message: err.Error()}
+		return response
+	}
+
+	data, err = json.Marshal(result)
+	if err != nil {
+		response.Error = &Error{Code: codeFailed, Message: err.Error()}
+		return response
+	}
+	response.Result = data
+
+	return response
+}
+
+// Close stops listening and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	os.Remove(s.path)
+	return err
+}
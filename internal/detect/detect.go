@@ -0,0 +1,329 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind is what an entry is suggested to become in the config
+type Kind string
+
+const (
+	KindProcess Kind = "process"
+	KindCommand Kind = "command"
+)
+
+// Entry is something runnable that was found in the project
+type Entry struct {
+	Source      string
+	Shortname   string
+	Command     string
+	Description string
+	Kind        Kind
+
+	// Service is set for docker compose services, which ren runs as compose processes
+	Service string
+}
+
+// longRunning are names of scripts and targets that usually keep running
+var longRunning = []string{"dev", "start", "serve", "server", "watch", "hot", "worker", "queue", "schedule"}
+
+// Project inspects the directory for package.json, composer.json, Procfile,
+// Makefile and docker compose files and returns everything it could run
+func Project(dir string) []Entry {
+	detectors := []func(string) ([]Entry, error){
+		packageJSON,
+		composerJSON,
+		procfile,
+		makefile,
+		dockerCompose,
+	}
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, detector := range detectors {
+		found, err := detector(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range found {
+			// the same name can show up in several files, e.g. a dev script and a dev target
+			if seen[entry.Shortname] {
+				entry.Shortname = fmt.Sprintf("%s (%s)", entry.Shortname, entry.Source)
+			}
+			seen[entry.Shortname] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func suggestKind(name string) Kind {
+	lower := strings.ToLower(name)
+	for _, word := range longRunning {
+		if lower == word || strings.HasPrefix(lower, word+":") || strings.HasSuffix(lower, ":"+word) ||
+			strings.HasPrefix(lower, word+"-") || strings.HasSuffix(lower, "-"+word) {
+			return KindProcess
+		}
+	}
+	return KindCommand
+}
+
+// packageManager guesses the package manager from the lockfile in the directory
+func packageManager(dir string) string {
+	lockfiles := []struct {
+		file    string
+		manager string
+	}{
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"package-lock.json", "npm"},
+	}
+
+	for _, lockfile := range lockfiles {
+		if _, err := os.Stat(filepath.Join(dir, lockfile.file)); err == nil {
+			return lockfile.manager
+		}
+	}
+
+	return "npm"
+}
+
+func packageJSON(dir string) ([]Entry, error) {
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := readJSON(filepath.Join(dir, "package.json"), &pkg); err != nil {
+		return nil, err
+	}
+
+	manager := packageManager(dir)
+
+	var entries []Entry
+	for _, name := range sortedKeys(pkg.Scripts) {
+		command := fmt.Sprintf("%s run %s", manager, name)
+		if manager == "yarn" {
+			command = fmt.Sprintf("yarn %s", name)
+		}
+
+		entries = append(entries, Entry{
+			Source:      "package.json",
+			Shortname:   name,
+			Command:     command,
+			Description: pkg.Scripts[name],
+			Kind:        suggestKind(name),
+		})
+	}
+
+	return entries, nil
+}
+
+func composerJSON(dir string) ([]Entry, error) {
+	var composer struct {
+		Scripts map[string]json.RawMessage `json:"scripts"`
+	}
+	if err := readJSON(filepath.Join(dir, "composer.json"), &composer); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, name := range sortedKeys(composer.Scripts) {
+		// skip the hooks composer runs around its own commands
+		if strings.HasPrefix(name, "pre-") || strings.HasPrefix(name, "post-") {
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Source:      "composer.json",
+			Shortname:   name,
+			Command:     fmt.Sprintf("composer run %s", name),
+			Description: describeComposerScript(composer.Scripts[name]),
+			Kind:        suggestKind(name),
+		})
+	}
+
+	return entries, nil
+}
+
+// describeComposerScript flattens a script, which can be a string or a list
+func describeComposerScript(raw json.RawMessage) string {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(raw, &multiple); err == nil {
+		return strings.Join(multiple, " && ")
+	}
+
+	return "composer script"
+}
+
+func procfile(dir string) ([]Entry, error) {
+	lines, err := readLines(filepath.Join(dir, "Procfile"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, line := range lines {
+		name, command, ok := strings.Cut(line, ":")
+		name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+		if !ok || name == "" || command == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Source:      "Procfile",
+			Shortname:   name,
+			Command:     command,
+			Description: "from the Procfile",
+			Kind:        KindProcess,
+		})
+	}
+
+	return entries, nil
+}
+
+var makeTarget = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*:([^=]|$)`)
+
+func makefile(dir string) ([]Entry, error) {
+	lines, err := readLines(filepath.Join(dir, "Makefile"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		match := makeTarget.FindStringSubmatch(line)
+		if match == nil || seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+
+		description := "make target"
+		// use the comment right above the target when there is one
+		if i > 0 && strings.HasPrefix(lines[i-1], "#") {
+			description = strings.TrimSpace(strings.TrimLeft(lines[i-1], "#"))
+		}
+
+		entries = append(entries, Entry{
+			Source:      "Makefile",
+			Shortname:   match[1],
+			Command:     fmt.Sprintf("make %s", match[1]),
+			Description: description,
+			Kind:        suggestKind(match[1]),
+		})
+	}
+
+	return entries, nil
+}
+
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+func dockerCompose(dir string) ([]Entry, error) {
+	for _, name := range composeFiles {
+		lines, err := readLines(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		var entries []Entry
+		for _, service := range composeServices(lines) {
+			entries = append(entries, Entry{
+				Source:      name,
+				Shortname:   service,
+				Command:     fmt.Sprintf("docker compose up %s", service),
+				Description: fmt.Sprintf("the %s service from %s", service, name),
+				Kind:        KindProcess,
+				Service:     service,
+			})
+		}
+
+		return entries, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+// composeServices picks the service names out of a compose file. it doesn't
+// parse yaml properly, it just looks for the keys directly under services.
+func composeServices(lines []string) []string {
+	var (
+		services   []string
+		inServices bool
+		indent     = -1
+	)
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		depth := len(line) - len(strings.TrimLeft(line, " \t"))
+		if depth == 0 {
+			inServices = strings.HasPrefix(trimmed, "services:")
+			continue
+		}
+
+		if !inServices {
+			continue
+		}
+
+		if indent < 0 {
+			indent = depth
+		}
+
+		if depth == indent && strings.HasSuffix(trimmed, ":") {
+			services = append(services, strings.Trim(strings.TrimSuffix(trimmed, ":"), `"'`))
+		}
+	}
+
+	return services
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines, scanner.Err()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,199 @@
+package keys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds every key binding of ren, which can be overridden in the
+// config under keys
+type KeyMap struct {
+	Quit       key.Binding
+	Help       key.Binding
+	Palette    key.Binding
+	NextTab    key.Binding
+	PrevTab    key.Binding
+	FocusNext  key.Binding
+	FocusPrev  key.Binding
+	Jump       key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	PageUp     key.Binding
+	PageDown   key.Binding
+	Top        key.Binding
+	Bottom     key.Binding
+	Trigger    key.Binding
+	Restart    key.Binding
+	Close      key.Binding
+	Clear      key.Binding
+	Insert     key.Binding
+	InsertLine key.Binding
+	Grid       key.Binding
+	Timestamps key.Binding
+	Warnings   key.Binding
+	Expand     key.Binding
+	Search     key.Binding
+	NextMatch  key.Binding
+	PrevMatch  key.Binding
+	Cancel     key.Binding
+	Select     key.Binding
+	Copy       key.Binding
+	CopyLast   key.Binding
+	CopyAll    key.Binding
+	PickPrev   key.Binding
+	PickNext   key.Binding
+	Mute       key.Binding
+}
+
+// Default returns the key bindings ren has out of the box
+func Default() KeyMap {
+	return KeyMap{
+		Quit:       binding("quit all", "q", "ctrl+c"),
+		Help:       binding("help", "?"),
+		Palette:    binding("command palette", "ctrl+p", ":"),
+		NextTab:    binding("next tab", "right", "l"),
+		PrevTab:    binding("previous tab", "left", "h"),
+		FocusNext:  binding("next tab, or pane in a view", "tab"),
+		FocusPrev:  binding("previous tab, or pane in a view", "shift+tab"),
+		Jump:       binding("jump to a process by its number", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
+		Up:         binding("scroll up, or select the previous command", "up", "k"),
+		Down:       binding("scroll down, or select the next command", "down", "j"),
+		PageUp:     binding("scroll a page up", "pgup"),
+		PageDown:   binding("scroll a page down", "pgdown"),
+		Top:        binding("scroll to the top", "home"),
+		Bottom:     binding("scroll to the bottom and follow", "end"),
+		Trigger:    binding("trigger the selected command", "enter"),
+		Restart:    binding("restart", "r"),
+		Close:      binding("close", "x"),
+		Clear:      binding("clear", "c"),
+		Insert:     binding("type into the process", "i"),
+		InsertLine: binding("send a line to the process", "I"),
+		Grid:       binding("add to or remove from the grid", "g"),
+		Timestamps: binding("cycle timestamps", "t"),
+		Warnings:   binding("only warnings and errors", "w"),
+		Expand:     binding("expand the json line", "e"),
+		Search:     binding("search", "/"),
+		NextMatch:  binding("older match", "n"),
+		PrevMatch:  binding("newer match", "N"),
+		Cancel:     binding("clear the search or cancel", "esc"),
+		Select:     binding("select lines", "v", "V"),
+		Copy:       binding("copy the selection or search match", "y"),
+		CopyLast:   binding("copy the last lines", "Y"),
+		CopyAll:    binding("copy everything", "C"),
+		PickPrev:   binding("pick the previous process", "["),
+		PickNext:   binding("pick the next process", "]"),
+		Mute:       binding("mute or unmute the picked process", " "),
+	}
+}
+
+func binding(description string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKey(keys), description))
+}
+
+// helpKey is how the keys of a binding are shown in the help, a range for
+// bindings with many keys like 1-9
+func helpKey(keys []string) string {
+	if len(keys) > 2 {
+		return Display(keys[0]) + "-" + Display(keys[len(keys)-1])
+	}
+	return Display(keys[0])
+}
+
+// Names maps the names used in the config to the bindings
+func (k *KeyMap) Names() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":        &k.Quit,
+		"help":        &k.Help,
+		"palette":     &k.Palette,
+		"next_tab":    &k.NextTab,
+		"prev_tab":    &k.PrevTab,
+		"focus_next":  &k.FocusNext,
+		"focus_prev":  &k.FocusPrev,
+		"jump":        &k.Jump,
+		"up":          &k.Up,
+		"down":        &k.Down,
+		"page_up":     &k.PageUp,
+		"page_down":   &k.PageDown,
+		"top":         &k.Top,
+		"bottom":      &k.Bottom,
+		"trigger":     &k.Trigger,
+		"restart":     &k.Restart,
+		"close":       &k.Close,
+		"clear":       &k.Clear,
+		"insert":      &k.Insert,
+		"insert_line": &k.InsertLine,
+		"grid":        &k.Grid,
+		"timestamps":  &k.Timestamps,
+		"warnings":    &k.Warnings,
+		"expand":      &k.Expand,
+		"search":      &k.Search,
+		"next_match":  &k.NextMatch,
+		"prev_match":  &k.PrevMatch,
+		"cancel":      &k.Cancel,
+		"select":      &k.Select,
+		"copy":        &k.Copy,
+		"copy_last":   &k.CopyLast,
+		"copy_all":    &k.CopyAll,
+		"pick_prev":   &k.PickPrev,
+		"pick_next":   &k.PickNext,
+		"mute":        &k.Mute,
+	}
+}
+
+// Apply replaces the keys of the bindings named in overrides
+func (k *KeyMap) Apply(overrides map[string][]string) error {
+	names := k.Names()
+	for name, keys := range overrides {
+		b, ok := names[name]
+		if !ok {
+			return fmt.Errorf("unknown key binding %q, use one of %s", name, strings.Join(sortedNames(names), ", "))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("key binding %q has no keys", name)
+		}
+
+		b.SetKeys(keys...)
+		b.SetHelp(helpKey(keys), b.Help().Desc)
+	}
+
+	return nil
+}
+
+// DisableProcessKeys turns off the bindings that change processes, for
+// looking at a recorded session
+func (k *KeyMap) DisableProcessKeys() {
+	for _, b := range []*key.Binding{&k.Trigger, &k.Restart, &k.Close, &k.Clear, &k.Insert, &k.InsertLine} {
+		b.SetEnabled(false)
+	}
+}
+
+func sortedNames(names map[string]*key.Binding) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Display shows a key the way it's written on the keyboard
+func Display(k string) string {
+	switch k {
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "enter":
+		return "↵"
+	case " ":
+		return "space"
+	}
+	return k
+}
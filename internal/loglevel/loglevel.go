@@ -0,0 +1,108 @@
+package loglevel
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// Level is how severe a line of log output is
+type Level int
+
+const (
+	// Unknown is for lines without a recognizable level
+	Unknown Level = iota
+	Trace
+	Debug
+	Info
+	Warn
+	Error
+	Fatal
+)
+
+var names = map[string]Level{
+	"trace":     Trace,
+	"debug":     Debug,
+	"dbg":       Debug,
+	"info":      Info,
+	"notice":    Info,
+	"warn":      Warn,
+	"warning":   Warn,
+	"error":     Error,
+	"err":       Error,
+	"fatal":     Fatal,
+	"panic":     Fatal,
+	"critical":  Fatal,
+	"crit":      Fatal,
+	"emergency": Fatal,
+	"alert":     Fatal,
+}
+
+var (
+	// structured logs, like level=warn in logfmt or "level":"warn" in JSON
+	fieldPattern = regexp.MustCompile(`(?i)"?\b(?:level|lvl|severity)"?\s*[:=]\s*"?([a-z]+)`)
+	// a level written in capitals, like Laravel's local.ERROR: or [WARN]
+	capitalsPattern = regexp.MustCompile(`\b(TRACE|DEBUG|DBG|INFO|NOTICE|WARN|WARNING|ERROR|ERR|FATAL|PANIC|CRITICAL|CRIT|EMERGENCY|ALERT)\b`)
+	// a lowercase level at the start, like warn: or error], or a level in
+	// brackets, like [error] or [Warning]
+	lowercasePattern = regexp.MustCompile(`^\W*([a-z]+)[:\]]|(?i:\[([a-z]+)\])`)
+	// a capitalized word at the start is mostly just a sentence, like
+	// Alert: or Notice:, but these two are how errors and warnings start
+	messagePattern = regexp.MustCompile(`^\W*(Error|Warning):`)
+)
+
+// maxPrefix is how far into a line the level is looked for, since it's
+// nearly always in front and the rest of a line can be long
+const maxPrefix = 120
+
+// Detect works out the level of a line of output, which is Unknown when
+// the line doesn't say
+func Detect(text string) Level {
+	text = utils.StripAnsi(text)
+	if len(text) > maxPrefix {
+		text = text[:maxPrefix]
+	}
+
+	if match := fieldPattern.FindStringSubmatch(text); match != nil {
+		if level, ok := names[strings.ToLower(match[1])]; ok {
+			return level
+		}
+	}
+
+	if match := capitalsPattern.FindStringSubmatch(text); match != nil {
+		return names[strings.ToLower(match[1])]
+	}
+
+	if match := messagePattern.FindStringSubmatch(text); match != nil {
+		return names[strings.ToLower(match[1])]
+	}
+
+	for _, match := range lowercasePattern.FindAllStringSubmatch(text, -1) {
+		for _, name := range match[1:] {
+			if level, ok := names[strings.ToLower(name)]; ok {
+				return level
+			}
+		}
+	}
+
+	return Unknown
+}
+
+func (l Level) String() string {
+	switch l {
+	case Trace:
+		return "trace"
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	case Fatal:
+		return "fatal"
+	}
+	return "unknown"
+}
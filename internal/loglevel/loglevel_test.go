@@ -0,0 +1,50 @@
+package loglevel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		line string
+		want Level
+	}{
+		{"laravel error", "[2024-05-01 10:00:00] local.ERROR: SQLSTATE[HY000] [2002] Connection refused", Error},
+		{"laravel warning", "[2024-05-01 10:00:00] production.WARNING: slow query", Warn},
+		{"bracketed capitals", "[ERROR] build failed", Error},
+		{"bracketed warn", "[WARN] the option foo is deprecated", Warn},
+		{"bare capitals", "WARN  retrying in 5s", Warn},
+		{"zap json", `{"level":"error","ts":1714550000.1,"msg":"request failed"}`, Error},
+		{"logrus json", `{"level":"warning","msg":"cache miss","time":"2024-05-01T10:00:00Z"}`, Warn},
+		{"logrus text", `time="2024-05-01T10:00:00Z" level=info msg="started"`, Info},
+		{"python basic", "ERROR:root:something broke", Error},
+		{"python module", "WARNING:urllib3.connectionpool:Retrying", Warn},
+		{"python formatted", "2024-05-01 10:00:00,123 - app - CRITICAL - out of memory", Fatal},
+		{"npm error", "npm ERR! code ELIFECYCLE", Error},
+		{"npm warning", "npm WARN deprecated request@2.88.2", Warn},
+		{"lowercase prefix", "error: could not compile `app`", Error},
+		{"lowercase warning", "warning: unused variable: `x`", Warn},
+		{"lowercase brackets", "[error] something went wrong", Error},
+		{"capitalized brackets", "[Warning] low disk space", Warn},
+		{"node error", "Error: listen EADDRINUSE: address already in use :::3000", Error},
+		{"colored", "\x1b[31mERROR\x1b[0m boom", Error},
+
+		{"capitalized sentence", "Alert: disk almost full", Unknown},
+		{"notice sentence", "Notice: the config file moved", Unknown},
+		{"level word in prose", "error handling improved in this release", Unknown},
+		{"plural in prose", "no errors found", Unknown},
+		{"longer capitals", "INFORMATION_SCHEMA query took 3ms", Unknown},
+		{"path", "GET /api/errors 200", Unknown},
+		{"unknown field value", "the level: high", Unknown},
+		{"plain", "Compiled successfully in 1.2s", Unknown},
+		{"past the prefix", strings.Repeat("x", maxPrefix) + " ERROR", Unknown},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.line); got != tt.want {
+				t.Errorf("Detect(%q) = %s, want %s", tt.line, got, tt.want)
+			}
+		})
+	}
+}
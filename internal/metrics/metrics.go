@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thejawker/rennen/internal/process"
+)
+
+// Source returns the processes and commands to export, as they are right now
+type Source func() (processes, commands []*process.Process)
+
+// states are the values of the ren_process_state state set
+var states = []string{"idle", "running", "exited", "stopped"}
+
+// Server serves the metrics of the processes in the Prometheus text format
+type Server struct {
+	source Source
+	server *http.Server
+}
+
+// Listen starts serving /metrics on addr, like ":9090". without a host it
+// only listens on 127.0.0.1.
+func Listen(addr string, source Source) (*Server, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	if host == "" {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &Server{source: source}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", s.serveMetrics)
+
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error serving metrics: %v\n", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	processes, commands := s.source()
+	Write(w, processes, commands)
+}
+
+// metric is a single metric family with one sample per process
+type metric struct {
+	name, kind, help string
+	samples          []sample
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// Write writes the metrics of the processes and commands in the Prometheus
+// text exposition format
+func Write(w io.Writer, processes, commands []*process.Process) {
+	families := []*metric{
+		{name: "ren_process_up", kind: "gauge", help: "Whether the process is running."},
+		{name: "ren_process_state", kind: "gauge", help: "The state the process is in, one series per state."},
+		{name: "ren_process_restarts_total", kind: "counter", help: "How often the process was started again."},
+		{name: "ren_process_last_exit_code", kind: "gauge", help: "The exit code of the last run that ended, -1 when it was killed."},
+		{name: "ren_process_uptime_seconds", kind: "gauge", help: "How long the current run has been going."},
+		{name: "ren_process_output_lines_total", kind: "counter", help: "Lines of output written over all runs."},
+		{name: "ren_process_output_bytes_total", kind: "counter", help: "Bytes of output written over all runs."},
+		{name: "ren_process_health_status", kind: "gauge", help: "The health of a compose service, one series with the current status."},
+		{name: "ren_process_cpu_seconds_total", kind: "counter", help: "CPU time used by the process and its descendants over all runs."},
+		{name: "ren_process_resident_memory_bytes", kind: "gauge", help: "Resident memory of the process and its descendants."},
+	}
+	byName := make(map[string]*metric, len(families))
+	for _, family := range families {
+		byName[family.name] = family
+	}
+
+	add := func(name string, value float64, labels ...string) {
+		byName[name].samples = append(byName[name].samples, sample{labels: labels, value: value})
+	}
+
+	collect := func(p *process.Process, kind string) {
+		labels := []string{"process", p.Shortname, "kind", kind}
+
+		state := p.State()
+		add("ren_process_up", boolValue(state == "running"), labels...)
+		for _, s := range states {
+			add("ren_process_state", boolValue(state == s), append(labels, "state", s)...)
+		}
+
+		add("ren_process_restarts_total", float64(p.Restarts()), labels...)
+		if code, ok := p.LastExitCode(); ok {
+			add("ren_process_last_exit_code", float64(code), labels...)
+		}
+		add("ren_process_uptime_seconds", p.Uptime().Seconds(), labels...)
+
+		lines, bytes := p.OutputTotals()
+		add("ren_process_output_lines_total", float64(lines), labels...)
+		add("ren_process_output_bytes_total", float64(bytes), labels...)
+
+		if p.IsCompose() {
+			if health := p.Health(); health != "" {
+				add("ren_process_health_status", 1, append(labels, "status", health)...)
+			}
+		}
+
+		// sampled along with the overview, so /proc isn't read for every scrape
+		if cpu, rss, ok := p.UsageTotals(); ok {
+			add("ren_process_cpu_seconds_total", cpu.Seconds(), labels...)
+			add("ren_process_resident_memory_bytes", float64(rss), labels...)
+		}
+	}
+
+	for _, p := range processes {
+		collect(p, "process")
+	}
+	for _, c := range commands {
+		collect(c, "command")
+	}
+
+	for _, family := range families {
+		if len(family.samples) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", family.name, family.kind)
+		for _, s := range family.samples {
+			fmt.Fprintf(w, "%s%s %s\n", family.name, formatLabels(s.labels), formatValue(s.value))
+		}
+	}
+}
+
+// formatLabels turns name, value pairs into {name="value",...}
+func formatLabels(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// labelEscaper escapes label values the way the exposition format wants
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
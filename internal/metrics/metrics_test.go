@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thejawker/rennen/internal/process"
+)
+
+func TestWriteExpositionFormat(t *testing.T) {
+	web := &process.Process{Shortname: "web", Command: "printf 'one\\ntwo\\n'; exit 3"}
+	if err := web.Start(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for web.State() != "exited" {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the process to exit")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	odd := &process.Process{Shortname: `say "hi"\now`, Command: "true"}
+
+	var out strings.Builder
+	Write(&out, []*process.Process{web}, []*process.Process{odd})
+	text := out.String()
+
+	for _, line := range []string{
+		"# HELP ren_process_up Whether the process is running.",
+		"# TYPE ren_process_up gauge",
+		`ren_process_up{process="web",kind="process"} 0`,
+		`ren_process_state{process="web",kind="process",state="exited"} 1`,
+		`ren_process_state{process="web",kind="process",state="running"} 0`,
+		"# TYPE ren_process_restarts_total counter",
+		`ren_process_last_exit_code{process="web",kind="process"} 3`,
+		`ren_process_output_lines_total{process="web",kind="process"} 2`,
+		`ren_process_state{process="say \"hi\"\\now",kind="command",state="idle"} 1`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("missing %s in\n%s", line, text)
+		}
+	}
+
+	// every sample belongs to the family whose HELP and TYPE came right
+	// before it
+	sampleLine := regexp.MustCompile(`^([a-z_]+)\{[^}]*\} -?[0-9.e+]+$`)
+	family := ""
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if name, ok := strings.CutPrefix(line, "# HELP "); ok {
+			family, _, _ = strings.Cut(name, " ")
+			continue
+		}
+		if strings.HasPrefix(line, "# TYPE "+family+" ") {
+			continue
+		}
+
+		match := sampleLine.FindStringSubmatch(line)
+		if match == nil || match[1] != family {
+			t.Errorf("line %q doesn't belong to the family %s", line, family)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/process"
+)
+
+// the actions below are what the keys do to processes. the control socket
+// calls the very same ones, so both behave the same.
+
+// do runs an action in the background, logging when it fails
+func (m *Model) do(action func(*process.Process) error, proc *process.Process, doing string) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(proc); err != nil {
+			log.Printf("Error %s %s: %v\n", doing, proc.Shortname, err)
+		}
+		return ProcessUpdateMsg{}
+	}
+}
+
+func (m *Model) restartProcess(proc *process.Process) tea.Cmd {
+	return m.do(m.restart, proc, "restarting process")
+}
+
+func (m *Model) closeProcess(proc *process.Process) tea.Cmd {
+	return m.do(m.stop, proc, "stopping process")
+}
+
+// TabStatusMsg sets the status of the tab of a process. actions run outside
+// the bubbletea loop, so they hand their changes to the tabs back to it,
+// without waiting for it since the control socket and the http api call
+// them after the loop may have quit.
+type TabStatusMsg struct {
+	Process *process.Process
+	Status  string
+	// Seen clears the notification of the tab
+	Seen bool
+}
+
+func (m *Model) restart(proc *process.Process) error {
+	err := proc.Restart()
+	m.post(TabStatusMsg{Process: proc})
+	return err
+}
+
+func (m *Model) stop(proc *process.Process) error {
+	err := proc.Stop()
+	m.post(TabStatusMsg{Process: proc, Status: "stopped", Seen: true})
+	return err
+}
+
+// setTabStatus applies a TabStatusMsg
+func (m *Model) setTabStatus(msg TabStatusMsg) {
+	if tab := m.GetTabForProcess(msg.Process); tab != nil {
+		tab.Status = msg.Status
+		if msg.Seen {
+			tab.Notification = false
+		}
+	}
+}
+
+// start starts a process that isn't running, like a restart would
+func (m *Model) start(proc *process.Process) error {
+	if proc.State() == "running" {
+		return fmt.Errorf("%s is already running", proc.Shortname)
+	}
+	return m.restart(proc)
+}
+
+func (m *Model) clear(proc *process.Process) error {
+	proc.ClearOutput()
+	return nil
+}
+
+// trigger runs a command
+func (m *Model) trigger(cmd *process.Process) error {
+	return cmd.Start()
+}
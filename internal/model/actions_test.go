@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thejawker/rennen/internal/control"
+	"github.com/thejawker/rennen/internal/process"
+)
+
+func TestControlDoesNotWaitForTheLoop(t *testing.T) {
+	p := &process.Process{Shortname: "api", Command: "sleep 30"}
+	m := New([]*process.Process{p}, nil)
+
+	// the loop quit, so nothing takes the events anymore
+	for i := 0; i < cap(m.events); i++ {
+		m.events <- tickMsg{}
+	}
+
+	for _, method := range []string{"restart", "stop"} {
+		done := make(chan error)
+		go func() {
+			_, err := m.HandleControl(method, control.Params{Name: "api"})
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("%s: %v", method, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s is waiting for the loop", method)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"log"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+// detachKey hands the keyboard back to ren while attached to a process
+const detachKey = "ctrl+]"
+
+// attach starts forwarding input to the active process
+func (m *Model) attach(mode types.InsertMode) tea.Cmd {
+	proc := m.GetActiveProcess()
+	if proc == nil || proc.IsStopped() {
+		return nil
+	}
+
+	m.InsertMode = mode
+	if mode == types.InsertLine {
+		m.Input = textinput.New()
+		m.Input.Prompt = "› "
+		return m.Input.Focus()
+	}
+
+	return nil
+}
+
+func (m *Model) detach() {
+	m.InsertMode = types.InsertNone
+	m.Input.Blur()
+}
+
+// handleAttachedKey forwards a key press to the attached process, either
+// straight away or once a whole line has been typed
+func (m *Model) handleAttachedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	proc := m.GetActiveProcess()
+	if proc == nil || proc.IsStopped() || msg.String() == detachKey {
+		m.detach()
+		return m, nil
+	}
+
+	if m.InsertMode == types.InsertLine {
+		switch msg.Type {
+		case tea.KeyEsc:
+			m.detach()
+			return m, nil
+		case tea.KeyEnter:
+			line := m.Input.Value() + "\n"
+			m.Input.Reset()
+			if _, err := proc.Write([]byte(line)); err != nil {
+				log.Printf("Error writing to %s: %v\n", proc.Shortname, err)
+			}
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.Input, cmd = m.Input.Update(msg)
+		return m, cmd
+	}
+
+	// there is no terminal in between, so ctrl+c has to become a signal
+	if msg.Type == tea.KeyCtrlC {
+		if err := proc.Interrupt(); err != nil {
+			log.Printf("Error interrupting %s: %v\n", proc.Shortname, err)
+		}
+		return m, nil
+	}
+
+	if data := keyBytes(msg); data != nil {
+		if _, err := proc.Write(data); err != nil {
+			log.Printf("Error writing to %s: %v\n", proc.Shortname, err)
+		}
+	}
+
+	return m, nil
+}
+
+// keyBytes translates a key press back into what a terminal would send
+func keyBytes(msg tea.KeyMsg) []byte {
+	var data []byte
+
+	switch msg.Type {
+	case tea.KeyRunes:
+		data = []byte(string(msg.Runes))
+	case tea.KeySpace:
+		data = []byte(" ")
+	case tea.KeyEnter:
+		data = []byte("\n")
+	case tea.KeyUp:
+		data = []byte("\x1b[A")
+	case tea.KeyDown:
+		data = []byte("\x1b[B")
+	case tea.KeyRight:
+		data = []byte("\x1b[C")
+	case tea.KeyLeft:
+		data = []byte("\x1b[D")
+	case tea.KeyHome:
+		data = []byte("\x1b[H")
+	case tea.KeyEnd:
+		data = []byte("\x1b[F")
+	case tea.KeyDelete:
+		data = []byte("\x1b[3~")
+	default:
+		// the remaining key types are the control characters themselves
+		if msg.Type >= 0 && msg.Type <= 127 {
+			data = []byte{byte(msg.Type)}
+		}
+	}
+
+	if data != nil && msg.Alt {
+		data = append([]byte("\x1b"), data...)
+	}
+
+	return data
+}
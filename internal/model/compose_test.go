@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/testutil"
+)
+
+func TestComposeHealthShowsInTabStatus(t *testing.T) {
+	dir := testutil.FakeDocker(t)
+
+	for _, health := range []string{"healthy", "unhealthy"} {
+		testutil.SetDockerPs(t, dir, `{"Service":"postgres","State":"running","Health":"`+health+`"}`)
+
+		p := &process.Process{Shortname: "db", Type: process.TypeCompose, Service: "postgres"}
+		m := New([]*process.Process{p}, nil)
+		if err := p.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for p.Health() == "" && time.Now().Before(deadline) {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		m.Update(m.updateNotifications()())
+
+		if tab := m.GetTabForProcess(p); tab == nil || tab.Status != health {
+			t.Errorf("tab of db is %+v, want status %q", tab, health)
+		}
+
+		if err := p.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		testutil.ResetDocker(t, dir)
+	}
+}
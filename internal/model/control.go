@@ -0,0 +1,206 @@
+package model
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/control"
+	"github.com/thejawker/rennen/internal/process"
+)
+
+// HandleControl answers the requests coming in over the control socket
+func (m *Model) HandleControl(method string, params control.Params) (interface{}, error) {
+	switch method {
+	case "list":
+		return m.controlList(), nil
+	case "status":
+		return m.controlStatus(), nil
+	case "tail":
+		return m.controlTail(params)
+	case "trigger":
+		cmd := m.lockedCommandByName(params.Name)
+		if cmd == nil {
+			return nil, fmt.Errorf("%w: there is no command called %q", control.ErrInvalidParams, params.Name)
+		}
+		return m.controlAction(m.trigger, cmd, "command")
+	}
+
+	actions := map[string]func(*process.Process) error{
+		"start":   m.start,
+		"stop":    m.stop,
+		"restart": m.restart,
+		"clear":   m.clear,
+	}
+
+	action, ok := actions[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", control.ErrMethodNotFound, method)
+	}
+
+	proc := m.lockedProcessByName(params.Name)
+	if proc == nil {
+		return nil, fmt.Errorf("%w: there is no process called %q", control.ErrInvalidParams, params.Name)
+	}
+
+	return m.controlAction(action, proc, "process")
+}
+
+// controlAction runs an action and returns the status of the process after it
+func (m *Model) controlAction(action func(*process.Process) error, proc *process.Process, kind string) (interface{}, error) {
+	if err := action(proc); err != nil {
+		return nil, err
+	}
+
+	m.send(ProcessUpdateMsg{})
+
+	return processStatus(proc, kind), nil
+}
+
+func (m *Model) controlList() []control.Entry {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	var entries []control.Entry
+	for _, p := range m.Processes {
+		entries = append(entries, control.Entry{Name: p.Shortname, Kind: "process", Command: p.Command, Description: p.Description})
+	}
+	for _, c := range m.Commands {
+		entries = append(entries, control.Entry{Name: c.Shortname, Kind: "command", Command: c.Command, Description: c.Description})
+	}
+
+	return entries
+}
+
+func (m *Model) controlStatus() []control.Status {
+	processes, commands := m.Snapshot()
+
+	var statuses []control.Status
+	for _, p := range processes {
+		statuses = append(statuses, processStatus(p, "process"))
+	}
+	for _, c := range commands {
+		statuses = append(statuses, processStatus(c, "command"))
+	}
+
+	return statuses
+}
+
+func processStatus(p *process.Process, kind string) control.Status {
+	status := control.Status{
+		Name:     p.Shortname,
+		Kind:     kind,
+		Command:  p.Command,
+		State:    p.State(),
+		Pid:      p.Pid(),
+		LastLine: p.GetLastNonEmptyLine(),
+	}
+
+	if p.IsCompose() {
+		status.Health = p.Health()
+	}
+
+	switch status.State {
+	case "running":
+		status.StartedAt = p.StartTime()
+	case "exited":
+		code := p.ExitCode()
+		status.ExitCode = &code
+	}
+
+	return status
+}
+
+func (m *Model) controlTail(params control.Params) ([]control.Line, error) {
+	proc := m.lockedProcessByName(params.Name)
+	if proc == nil {
+		proc = m.lockedCommandByName(params.Name)
+	}
+	if proc == nil {
+		return nil, fmt.Errorf("%w: there is no process called %q", control.ErrInvalidParams, params.Name)
+	}
+
+	n := params.Lines
+	if n <= 0 {
+		n = 100
+	}
+
+	lines := []control.Line{}
+	for _, line := range proc.Since(params.After, n) {
+		lines = append(lines, control.Line{Seq: line.Seq, Time: line.Time, Stream: line.Stream.String(), Text: line.Text})
+	}
+
+	return lines, nil
+}
+
+// Snapshot returns the processes and commands as they are right now, for use
+// outside the bubbletea loop
+func (m *Model) Snapshot() (processes, commands []*process.Process) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	return m.Processes, m.Commands
+}
+
+func (m *Model) lockedProcessByName(name string) *process.Process {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	return m.GetProcessByName(name)
+}
+
+func (m *Model) lockedCommandByName(name string) *process.Process {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	return m.GetCommandByName(name)
+}
+
+// send pushes a message into the bubbletea loop from another goroutine,
+// dropping it when the loop is too busy to take it. that's only fine for
+// hints to refresh, actions go through post.
+func (m *Model) send(msg tea.Msg) {
+	select {
+	case m.events <- msg:
+	default:
+	}
+}
+
+// post pushes a message into the bubbletea loop without ever dropping it. it
+// doesn't wait for the loop to take it either, since the caller may hold a
+// lock the loop needs, like the one of a process writing output, or the loop
+// may be gone already. messages the loop can't take yet are queued and
+// handed to it in order by a single goroutine.
+func (m *Model) post(msg tea.Msg) {
+	m.pendingMutex.Lock()
+	defer m.pendingMutex.Unlock()
+
+	if len(m.pending) == 0 {
+		select {
+		case m.events <- msg:
+			return
+		default:
+		}
+	}
+
+	m.pending = append(m.pending, msg)
+	if len(m.pending) == 1 {
+		go m.deliver()
+	}
+}
+
+// deliver hands the queued messages to the loop, until there are none left
+func (m *Model) deliver() {
+	for {
+		m.pendingMutex.Lock()
+		msg := m.pending[0]
+		m.pendingMutex.Unlock()
+
+		m.events <- msg
+
+		m.pendingMutex.Lock()
+		m.pending = m.pending[1:]
+		done := len(m.pending) == 0
+		m.pendingMutex.Unlock()
+
+		if done {
+			return
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/terminal"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// Selection is the state of selecting lines of output to copy with 'v'
+type Selection struct {
+	Active bool
+	// Anchor is the line the selection started at and Cursor the line it
+	// extends to, both by sequence number
+	Anchor uint64
+	Cursor uint64
+	// Prompting is set while the number of lines for 'Y' is being typed
+	Prompting bool
+	// Status tells what was copied, until the next key press
+	Status string
+}
+
+// startSelection selects the line at the bottom of the screen
+func (m *Model) startSelection() {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	scroll, _ := m.activeScroll()
+	lines := m.searchLines()
+	if scroll == nil || len(lines) == 0 {
+		return
+	}
+
+	line := lines[max(0, len(lines)-1-scroll.Offset)]
+	m.Selection = Selection{Active: true, Anchor: line.Seq, Cursor: line.Seq}
+}
+
+func (m *Model) handleSelectionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.Keys
+	switch {
+	case key.Matches(msg, k.Up):
+		m.moveSelection(-1)
+	case key.Matches(msg, k.Down):
+		m.moveSelection(1)
+	case key.Matches(msg, k.PageUp):
+		m.moveSelection(-m.pageSize())
+	case key.Matches(msg, k.PageDown):
+		m.moveSelection(m.pageSize())
+	case key.Matches(msg, k.Top):
+		m.moveSelection(-maxLinesInView)
+	case key.Matches(msg, k.Bottom):
+		m.moveSelection(maxLinesInView)
+	case key.Matches(msg, k.Expand):
+		m.toggleExpanded()
+	case key.Matches(msg, k.Copy), msg.Type == tea.KeyEnter:
+		m.copySelection()
+	case key.Matches(msg, k.Cancel, k.Select, k.Quit):
+		m.Selection = Selection{}
+	}
+	return m, nil
+}
+
+// maxLinesInView is more lines than any output holds, to move to either end
+const maxLinesInView = 1 << 30
+
+// moveSelection moves the cursor of the selection by a number of lines,
+// scrolling to keep it on screen
+func (m *Model) moveSelection(delta int) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	scroll, counts := m.activeScroll()
+	lines := m.searchLines()
+	if scroll == nil || counts == nil || len(lines) == 0 {
+		return
+	}
+
+	index := len(lines) - 1
+	for i, line := range lines {
+		if line.Seq >= m.Selection.Cursor {
+			index = i
+			break
+		}
+	}
+
+	index = max(0, min(len(lines)-1, index+delta))
+	m.Selection.Cursor = lines[index].Seq
+
+	// the offset counts from the bottom, like the cursor's distance to it
+	fromBottom := len(lines) - 1 - index
+	page := m.pageSize()
+	if fromBottom < scroll.Offset {
+		scroll.Offset = fromBottom
+	} else if fromBottom >= scroll.Offset+page {
+		scroll.Offset = fromBottom - page + 1
+	}
+	_, scroll.Lines = counts()
+}
+
+// copySelection copies the selected lines and ends the selection
+func (m *Model) copySelection() {
+	from, to := m.Selection.Anchor, m.Selection.Cursor
+	if from > to {
+		from, to = to, from
+	}
+
+	m.Mutex.Lock()
+	var selected []process.Line
+	for _, line := range m.searchLines() {
+		if line.Seq >= from && line.Seq <= to {
+			selected = append(selected, line)
+		}
+	}
+	m.Mutex.Unlock()
+
+	m.Selection = Selection{}
+	m.copyLines(selected)
+}
+
+// copySearchMatch copies the line of the current search match
+func (m *Model) copySearchMatch() {
+	if m.Search.Seq == 0 {
+		m.Selection.Status = "nothing to copy, search with / or select with v first"
+		return
+	}
+
+	m.Mutex.Lock()
+	var match []process.Line
+	for _, line := range m.searchLines() {
+		if line.Seq == m.Search.Seq {
+			match = append(match, line)
+		}
+	}
+	m.Mutex.Unlock()
+
+	m.copyLines(match)
+}
+
+// copyLast copies the last n lines of the output of the active tab, or all
+// of them when n is 0
+func (m *Model) copyLast(n int) {
+	m.Mutex.Lock()
+	lines := m.searchLines()
+	m.Mutex.Unlock()
+
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	m.copyLines(lines)
+}
+
+// copyLines puts the lines on the clipboard without their colors
+func (m *Model) copyLines(lines []process.Line) {
+	if len(lines) == 0 {
+		m.Selection.Status = "nothing to copy"
+		return
+	}
+
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = utils.StripAnsi(line.Text)
+	}
+
+	terminal.Copy(strings.Join(texts, "\n"))
+
+	m.Selection.Status = fmt.Sprintf("copied %d lines", len(lines))
+	if len(lines) == 1 {
+		m.Selection.Status = "copied 1 line"
+	}
+}
+
+// startCopyPrompt asks for the number of lines 'Y' copies
+func (m *Model) startCopyPrompt() tea.Cmd {
+	if m.Tabs[m.ActiveTab].Kind == types.TabOverview {
+		return nil
+	}
+
+	m.Input = textinput.New()
+	m.Input.Prompt = "copy last "
+	m.Input.Placeholder = "100"
+	m.Input.CharLimit = 9
+	m.Input.Validate = func(s string) error {
+		if _, err := strconv.Atoi(s); s != "" && err != nil {
+			return err
+		}
+		return nil
+	}
+	m.Selection.Prompting = true
+
+	return m.Input.Focus()
+}
+
+func (m *Model) handleCopyPromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.Selection.Prompting = false
+		m.Input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		m.Selection.Prompting = false
+		m.Input.Blur()
+		n, err := strconv.Atoi(m.Input.Value())
+		if err != nil {
+			n = 100
+		}
+		m.copyLast(max(1, n))
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.Input, cmd = m.Input.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"log"
+	"math"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/keys"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+// SetKeys rebuilds the key bindings from the defaults and the overrides of
+// the config, which have been validated when it was loaded
+func (m *Model) SetKeys(overrides map[string][]string) {
+	m.Keys = keys.Default()
+	if err := m.Keys.Apply(overrides); err != nil {
+		log.Printf("Error applying key bindings: %v\n", err)
+	}
+	if m.ReadOnly {
+		m.Keys.DisableProcessKeys()
+	}
+}
+
+// handleKey does what the key binding of a key press says
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := m.Keys
+
+	switch {
+	case key.Matches(msg, k.Quit):
+		return m, m.Shutdown()
+	case key.Matches(msg, k.Help):
+		m.ShowHelp = true
+		return m, nil
+	case key.Matches(msg, k.Palette):
+		return m, m.openPalette()
+	case key.Matches(msg, k.FocusNext, k.FocusPrev):
+		delta := 1
+		if key.Matches(msg, k.FocusPrev) {
+			delta = -1
+		}
+		// in a view tab these move the focus between the panes instead
+		if m.Tabs[m.ActiveTab].Kind == types.TabView {
+			m.focusPane(delta)
+			return m, nil
+		}
+		return m.switchTab(delta)
+	case key.Matches(msg, k.NextTab):
+		return m.switchTab(1)
+	case key.Matches(msg, k.PrevTab):
+		return m.switchTab(-1)
+	case key.Matches(msg, k.Jump):
+		// the keys of the binding are the processes in order
+		number := slices.Index(k.Jump.Keys(), msg.String()) + 1
+		if index := types.ProcessTab(m.Tabs, number); index >= 0 {
+			m.ActiveTab = index
+			return m.ClearNotification(index)
+		}
+	case key.Matches(msg, k.Up):
+		if m.ActiveTab != 0 {
+			m.scrollActive(1)
+			return m, nil
+		}
+		if len(m.Commands) == 0 {
+			return m, nil
+		}
+		m.SelectedCommand = (m.SelectedCommand - 1 + len(m.Commands)) % len(m.Commands)
+		log.Printf("Selected command: %d\n", m.SelectedCommand)
+		return m, nil
+	case key.Matches(msg, k.Down):
+		if m.ActiveTab != 0 {
+			m.scrollActive(-1)
+			return m, nil
+		}
+		if len(m.Commands) == 0 {
+			return m, nil
+		}
+		m.SelectedCommand = (m.SelectedCommand + 1) % len(m.Commands)
+		log.Printf("Selected command: %d\n", m.SelectedCommand)
+		return m, nil
+	case key.Matches(msg, k.Trigger):
+		if m.ActiveTab != 0 {
+			return m, nil
+		}
+		if m.SelectedCommand >= 0 && m.SelectedCommand < len(m.Commands) {
+			return m, m.do(m.trigger, m.Commands[m.SelectedCommand], "triggering")
+		}
+	case key.Matches(msg, k.PageUp):
+		m.scrollActive(m.pageSize())
+	case key.Matches(msg, k.PageDown):
+		m.scrollActive(-m.pageSize())
+	case key.Matches(msg, k.Top):
+		m.scrollActive(math.MaxInt32)
+	case key.Matches(msg, k.Bottom):
+		m.scrollActive(-math.MaxInt32)
+	case key.Matches(msg, k.Timestamps):
+		m.Timestamps = (m.Timestamps + 1) % 3
+	case key.Matches(msg, k.Warnings):
+		m.toggleLevelFilter()
+	case key.Matches(msg, k.Expand):
+		m.toggleExpanded()
+	case key.Matches(msg, k.Select):
+		m.startSelection()
+	case key.Matches(msg, k.Copy):
+		m.copySearchMatch()
+	case key.Matches(msg, k.CopyLast):
+		return m, m.startCopyPrompt()
+	case key.Matches(msg, k.CopyAll):
+		m.copyLast(0)
+	case key.Matches(msg, k.PickPrev, k.PickNext):
+		if m.Tabs[m.ActiveTab].Kind == types.TabLogs {
+			delta := 1
+			if key.Matches(msg, k.PickPrev) {
+				delta = -1
+			}
+			m.moveLegendCursor(delta)
+		}
+	case key.Matches(msg, k.Mute):
+		if m.Tabs[m.ActiveTab].Kind == types.TabLogs {
+			m.toggleMuted()
+		}
+	case key.Matches(msg, k.Grid):
+		if proc := m.GetActiveProcess(); proc != nil {
+			m.toggleGrid(proc)
+		}
+	case key.Matches(msg, k.Close):
+		if proc := m.GetActiveProcess(); proc != nil {
+			return m, m.closeProcess(proc)
+		}
+	case key.Matches(msg, k.Clear):
+		if proc := m.GetActiveProcess(); proc != nil {
+			return m, m.do(m.clear, proc, "clearing")
+		}
+	case key.Matches(msg, k.Restart):
+		if proc := m.GetActiveProcess(); proc != nil {
+			return m, m.restartProcess(proc)
+		}
+	case key.Matches(msg, k.Search):
+		return m, m.startSearch()
+	case key.Matches(msg, k.NextMatch, k.PrevMatch):
+		m.nextMatch(key.Matches(msg, k.NextMatch))
+	case key.Matches(msg, k.Cancel):
+		m.clearSearch()
+	case key.Matches(msg, k.Insert):
+		return m, m.attach(types.InsertKeys)
+	case key.Matches(msg, k.InsertLine):
+		return m, m.attach(types.InsertLine)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,45 @@
+package model
+
+import "github.com/thejawker/rennen/internal/types"
+
+// logsTab is the name of the tab that interleaves the output of all processes
+const logsTab = "all logs"
+
+// moveLegendCursor moves the cursor over the processes in the legend of the
+// logs tab, which is used to pick the process to mute
+func (m *Model) moveLegendCursor(delta int) {
+	if len(m.Processes) == 0 {
+		return
+	}
+
+	m.LegendCursor = (m.LegendCursor + delta + len(m.Processes)) % len(m.Processes)
+}
+
+// toggleMuted hides or shows the process under the legend cursor in the logs tab
+func (m *Model) toggleMuted() {
+	if m.LegendCursor >= len(m.Processes) {
+		return
+	}
+
+	// the lines shift around, so jump back to following the output
+	m.Tabs[m.ActiveTab].Scroll = types.Scroll{}
+
+	name := m.Processes[m.LegendCursor].Shortname
+	if m.Muted[name] {
+		delete(m.Muted, name)
+		return
+	}
+	m.Muted[name] = true
+}
+
+// logsLineCounts sums the line counts of the processes shown in the logs tab
+func (m *Model) logsLineCounts() (count, total int) {
+	for _, p := range m.Processes {
+		if m.Muted[p.Shortname] {
+			continue
+		}
+		count += p.LineCount()
+		total += p.TotalLines()
+	}
+	return count, total
+}
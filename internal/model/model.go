@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/thejawker/rennen/internal/utils"
 	"log"
@@ -8,9 +9,13 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/keys"
 	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/session"
 	"github.com/thejawker/rennen/internal/types"
 	"github.com/thejawker/rennen/internal/ui"
+	"github.com/thejawker/rennen/internal/watcher"
 )
 
 type Model struct {
@@ -23,84 +28,137 @@ type Model struct {
 	Mutex           sync.Mutex
 	StartedAt       time.Time
 	SelectedCommand int
+	ConfigPath      string
+	ConfigError     string
+	events          chan tea.Msg
+	pending         []tea.Msg
+	pendingMutex    sync.Mutex
+	watchers        []*watcher.Watcher
+	watchMutex      sync.Mutex
+	Views           []config.ViewConfig
+	Muted           map[string]bool
+	LegendCursor    int
+	Timestamps      types.TimestampMode
+	InsertMode      types.InsertMode
+	Input           textinput.Model
+	Recorder        *session.Recorder
+	Search          Search
+	Selection       Selection
+	Palette         Palette
+	Keys            keys.KeyMap
+	ShowHelp        bool
+	Layout          types.Layout
+
+	// CPUWarning and MemoryWarning are the usage above which a process is
+	// highlighted in the overview
+	CPUWarning    float64
+	MemoryWarning int64
+
+	// triggered is when each trigger last acted, by process and pattern
+	triggered      map[string]time.Time
+	triggeredMutex sync.Mutex
+
+	// ReadOnly is set when looking at a recorded session, the processes
+	// can't be started or changed then
+	ReadOnly bool
+	Replay   *session.Manifest
 }
 
 func New(processes, commands []*process.Process) *Model {
-	tabs := make([]types.Tab, len(processes)+1)
-	tabs[0] = types.Tab{Name: "overview", Notification: false}
+	tabs := make([]types.Tab, len(processes)+2)
+	tabs[0] = types.Tab{Name: "overview", Notification: false, Kind: types.TabOverview}
+	tabs[1] = types.Tab{Name: logsTab, Notification: false, Kind: types.TabLogs}
 	for i, p := range processes {
-		tabs[i+1] = types.Tab{Name: p.Shortname, Notification: false}
+		tabs[i+2] = types.Tab{Name: p.Shortname, Notification: false, Kind: types.TabProcess}
 	}
 
-	// attach
-	return &Model{
+	m := &Model{
 		Processes:       processes,
 		Commands:        commands,
 		SelectedCommand: 0,
 		Tabs:            tabs,
 		ActiveTab:       0,
 		StartedAt:       time.Now(),
+		Muted:           make(map[string]bool),
+		Keys:            keys.Default(),
+		CPUWarning:      90,
+		MemoryWarning:   1 * config.GB,
+		triggered:       make(map[string]time.Time),
+		events:          make(chan tea.Msg, 16),
 	}
+
+	for _, p := range processes {
+		m.watchTriggers(p)
+	}
+
+	return m
 }
 
 func (m *Model) Init() tea.Cmd {
-	cmdList := append(m.startAllProcesses(), tick)
+	if m.ReadOnly {
+		return tea.Batch(tick, m.listen())
+	}
+
+	cmdList := append(m.startAllProcesses(), tick, m.listen(), m.watchProcesses(), m.sampleUsage())
+	if m.ConfigPath != "" {
+		cmdList = append(cmdList, m.watchConfig())
+	}
 	return tea.Batch(cmdList...)
 }
 
+// listen waits for the next message pushed onto the events channel by
+// goroutines that live outside the bubbletea loop
+func (m *Model) listen() tea.Cmd {
+	return func() tea.Msg {
+		return eventMsg{msg: <-m.events}
+	}
+}
+
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, m.Shutdown()
-		case "tab", "right", "l":
-			m.ActiveTab = (m.ActiveTab + 1) % len(m.Tabs)
-			return m.ClearNotification(m.ActiveTab)
-		case "shift+tab", "left", "h":
-			m.ActiveTab = (m.ActiveTab - 1 + len(m.Tabs)) % len(m.Tabs)
-			return m.ClearNotification(m.ActiveTab)
-		case "up":
-			if m.ActiveTab != 0 {
-				return m, nil
-			}
-			m.SelectedCommand = (m.SelectedCommand - 1 + len(m.Commands)) % len(m.Commands)
-			log.Printf("Selected command: %d\n", m.SelectedCommand)
-			return m, nil
-		case "down":
-			if m.ActiveTab != 0 {
-				return m, nil
-			}
-			m.SelectedCommand = (m.SelectedCommand + 1) % len(m.Commands)
-			log.Printf("Selected command: %d\n", m.SelectedCommand)
+		if m.InsertMode != types.InsertNone {
+			return m.handleAttachedKey(msg)
+		}
+
+		if m.Search.Typing {
+			return m.handleSearchKey(msg)
+		}
+
+		m.Selection.Status = ""
+		if m.Selection.Prompting {
+			return m.handleCopyPromptKey(msg)
+		}
+		if m.Selection.Active {
+			return m.handleSelectionKey(msg)
+		}
+
+		if m.Palette.Open {
+			return m.handlePaletteKey(msg)
+		}
+
+		if m.ShowHelp {
+			m.ShowHelp = false
 			return m, nil
-		case "enter":
-			if m.ActiveTab != 0 {
-				return m, nil
-			}
-			if m.SelectedCommand >= 0 && m.SelectedCommand < len(m.Commands) {
-				return m, m.startProcess(m.Commands[m.SelectedCommand])
-			}
-		case "x":
-			if m.ActiveTab > 0 && m.ActiveTab <= len(m.Processes) {
-				return m, m.closeProcess(m.GetActiveProcess())
-			}
-		case "c":
-			if m.ActiveTab > 0 && m.ActiveTab <= len(m.Processes) {
-				proc := m.GetActiveProcess()
-				return m, func() tea.Msg {
-					proc.ClearOutput()
-					return ProcessUpdateMsg{}
-				}
-			}
-		case "r":
-			if m.ActiveTab > 0 && m.ActiveTab <= len(m.Processes) {
-				proc := m.GetActiveProcess()
-				return m, m.restartProcess(proc)
-			}
 		}
+
+		return m.handleKey(msg)
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 	case tea.WindowSizeMsg:
 		m.WindowSize = msg
+	case eventMsg:
+		_, cmd := m.Update(msg.msg)
+		return m, tea.Batch(cmd, m.listen())
+	case ConfigChangedMsg:
+		return m, m.reloadConfig()
+	case FileChangedMsg:
+		return m, m.restartForChange(msg)
+	case TriggerMsg:
+		return m, m.runTrigger(msg)
+	case TabStatusMsg:
+		m.setTabStatus(msg)
+		return m, nil
 	case ProcessStartedMsg:
 		log.Printf("Process started: %s\n", msg.Process.Shortname)
 		return m, m.updateNotifications()
@@ -109,45 +167,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case ProcessUpdateMsg, OutputMsg, tickMsg:
 		return m, m.updateNotifications()
+	case NotificationsMsg:
+		m.applyNotifications(msg)
+		return m, tick
 	}
 
 	return m, nil
 }
 
-func (m *Model) restartProcess(proc *process.Process) tea.Cmd {
-	return func() tea.Msg {
-		if err := proc.Restart(); err != nil {
-			log.Printf("Error restarting process %s: %v\n", proc.Shortname, err)
-		}
-
-		tab := m.GetTabForProcess(proc)
-		if tab != nil {
-			tab.Status = ""
-		}
-		return ProcessUpdateMsg{}
-	}
-}
-
-func (m *Model) closeProcess(proc *process.Process) tea.Cmd {
-	return func() tea.Msg {
-		if err := proc.Stop(); err != nil {
-			log.Printf("Error stopping process %s: %v\n", proc.Shortname, err)
-		}
-		tab := m.GetTabForProcess(proc)
-		if tab != nil {
-			tab.Status = "stopped"
-			tab.Notification = false
-		}
-		return ProcessUpdateMsg{}
-	}
-}
-
 type OutputMsg struct {
 	process *process.Process
 }
 
 func (m *Model) Shutdown() tea.Cmd {
 	return func() tea.Msg {
+		m.closeWatchers()
+
 		var wg sync.WaitGroup
 		for _, p := range m.Processes {
 			wg.Add(1)
@@ -164,19 +199,18 @@ func (m *Model) Shutdown() tea.Cmd {
 	}
 }
 
+func (m *Model) switchTab(delta int) (tea.Model, tea.Cmd) {
+	m.ActiveTab = (m.ActiveTab + delta + len(m.Tabs)) % len(m.Tabs)
+	return m.ClearNotification(m.ActiveTab)
+}
+
+// ClearNotification marks all output of the process of a tab as seen
 func (m *Model) ClearNotification(tabIndex int) (tea.Model, tea.Cmd) {
-	var tab types.Tab
 	if tabIndex > 0 && tabIndex < len(m.Tabs) {
-		tab = m.Tabs[tabIndex]
-		tab.Notification = false
-	}
-
-	proc := m.GetProcessForTab(tab)
-
-	if proc != nil {
-		// Reset the last activity time
-		log.Printf("Clearing notification for %s\n", proc.Shortname)
-		proc.LastActivity = time.Now().Add(-time.Minute * 10)
+		tab := &m.Tabs[tabIndex]
+		if proc := m.GetProcessForTab(*tab); proc != nil {
+			markSeen(tab, countOutput(proc))
+		}
 	}
 
 	return m, func() tea.Msg {
@@ -185,8 +219,15 @@ func (m *Model) ClearNotification(tabIndex int) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) GetProcessForTab(tab types.Tab) *process.Process {
+	if tab.Kind != types.TabProcess {
+		return nil
+	}
+	return m.GetProcessByName(tab.Name)
+}
+
+func (m *Model) GetProcessByName(name string) *process.Process {
 	for _, p := range m.Processes {
-		if p.Shortname == tab.Name {
+		if p.Shortname == name {
 			return p
 		}
 	}
@@ -205,30 +246,110 @@ func (m *Model) GetActiveCommands() []*process.Process {
 	return activeCommands
 }
 
-// updateNotifications checks the last activity time of each process and sets
+// NotificationsMsg has how much output each process wrote so far, for the
+// tabs to be updated with inside the loop
+type NotificationsMsg struct {
+	Output map[*process.Process]outputCounts
+}
+
+// outputCounts is what the tab of a process shows about its output
+type outputCounts struct {
+	lines, errors uint64
+	// health is the health of a running compose service
+	health string
+}
+
+func countOutput(p *process.Process) outputCounts {
+	counts := outputCounts{errors: p.ErrorTotal()}
+	counts.lines, _ = p.OutputTotals()
+	if p.IsCompose() && !p.IsStopped() {
+		counts.health = p.Health()
+	}
+	return counts
+}
+
+// updateNotifications counts the lines and errors each process wrote. the
+// tabs belong to the loop, so applyNotifications updates them from there.
 func (m *Model) updateNotifications() tea.Cmd {
+	processes, _ := m.Snapshot()
+
 	return func() tea.Msg {
-		m.Mutex.Lock()
-		defer m.Mutex.Unlock()
-		for i, t := range m.Tabs {
-			if i == 0 {
-				continue // Skip the "all" tab
-			}
-			for _, p := range m.Processes {
-				if p.Shortname == t.Name {
-					if m.GetActiveTabName() == t.Name {
-						m.Tabs[i].Notification = false
-						break
-					}
-
-					m.Tabs[i].Notification = time.Since(p.LastActivity) < time.Minute
-					break
-				}
-			}
+		output := make(map[*process.Process]outputCounts, len(processes))
+		for _, p := range processes {
+			output[p] = countOutput(p)
+		}
+		return NotificationsMsg{Output: output}
+	}
+}
+
+// applyNotifications works out the lines and errors each process wrote since
+// its tab was last looked at, and sets the notification according to the
+// process's notify rule
+func (m *Model) applyNotifications(msg NotificationsMsg) {
+	m.followOutput()
+
+	visible := m.visibleProcesses()
+
+	for i, t := range m.Tabs {
+		if t.Kind != types.TabProcess {
+			continue // Skip the overview and view tabs
 		}
 
-		return tick()
+		p := m.GetProcessByName(t.Name)
+		counts, ok := msg.Output[p]
+		if !ok {
+			continue
+		}
+
+		tab := &m.Tabs[i]
+		if counts.health != "" {
+			tab.Status = counts.health
+		}
+
+		if visible[t.Name] {
+			markSeen(tab, counts)
+			tab.Alert = ""
+		}
+
+		// the tab may have been looked at after the output was counted
+		tab.Unread = max(0, int(counts.lines)-int(tab.SeenLines))
+		tab.UnreadErrors = max(0, int(counts.errors)-int(tab.SeenErrors))
+
+		switch p.Notify {
+		case "never":
+			tab.Notification = false
+		case "errors":
+			tab.Notification = tab.UnreadErrors > 0
+		default:
+			tab.Notification = tab.Unread > 0
+		}
+	}
+}
+
+// markSeen remembers how much output the process had when its tab was last
+// looked at
+func markSeen(tab *types.Tab, counts outputCounts) {
+	tab.SeenLines, tab.SeenErrors = counts.lines, counts.errors
+	tab.Unread, tab.UnreadErrors = 0, 0
+	tab.Notification = false
+}
+
+// visibleProcesses returns the processes whose output is on screen, which
+// is the one of the active tab or all panes of the active view
+func (m *Model) visibleProcesses() map[string]bool {
+	visible := make(map[string]bool)
+
+	tab := m.Tabs[m.ActiveTab]
+	switch tab.Kind {
+	case types.TabProcess:
+		visible[tab.Name] = true
+	case types.TabView:
+		for _, pane := range tab.Panes {
+			visible[pane.Process] = true
+		}
 	}
+
+	return visible
 }
 
 func (m *Model) ScrollOutput(amount int) {
@@ -250,14 +371,47 @@ func (m *Model) GetViewModel() types.Model {
 		StartedAt:       m.StartedAt,
 		Commands:        m.Commands,
 		SelectedCommand: m.SelectedCommand,
+		ConfigError:     m.ConfigError,
+		InsertMode:      m.InsertMode,
+		InputLine:       m.Input.View(),
+		Muted:           m.Muted,
+		LegendCursor:    m.LegendCursor,
+		Timestamps:      m.Timestamps,
+		Search:          m.Search.Query,
+		Searching:       m.Search.Typing,
+		SearchSeq:       m.Search.Seq,
+		SearchStatus:    m.Search.Status,
+		Prompting:       m.Selection.Prompting,
+		Selecting:       m.Selection.Active,
+		SelectionFrom:   min(m.Selection.Anchor, m.Selection.Cursor),
+		SelectionTo:     max(m.Selection.Anchor, m.Selection.Cursor),
+		CopyStatus:      m.Selection.Status,
+		Keys:            m.Keys,
+		ShowHelp:        m.ShowHelp,
+		PaletteOpen:     m.Palette.Open,
+		PaletteEntries:  m.paletteTitles(),
+		PaletteSelected: m.Palette.Selected,
+		Layout:          m.Layout,
+		Replay:          m.replayBanner(),
+		ReadOnly:        m.ReadOnly,
+		CPUWarning:      m.CPUWarning,
+		MemoryWarning:   m.MemoryWarning,
 	}
 }
 
+// GetActiveProcess returns the process of the active tab, or the focused
+// pane's process when a view is active
 func (m *Model) GetActiveProcess() *process.Process {
-	if m.ActiveTab == 0 || m.ActiveTab > len(m.Processes) {
-		return nil
+	tab := m.Tabs[m.ActiveTab]
+	switch tab.Kind {
+	case types.TabProcess:
+		return m.GetProcessForTab(tab)
+	case types.TabView:
+		if tab.Focus < len(tab.Panes) {
+			return m.GetProcessByName(tab.Panes[tab.Focus].Process)
+		}
 	}
-	return m.Processes[m.ActiveTab-1]
+	return nil
 }
 
 func (m *Model) GetActiveTabName() string {
@@ -293,7 +447,7 @@ func (m *Model) startProcess(p *process.Process) func() tea.Msg {
 
 func (m *Model) GetTabForProcess(proc *process.Process) *types.Tab {
 	for i, t := range m.Tabs {
-		if t.Name == proc.Shortname {
+		if t.Kind == types.TabProcess && t.Name == proc.Shortname {
 			return &m.Tabs[i]
 		}
 	}
@@ -320,6 +474,14 @@ type ProcessErrorMsg struct {
 
 type ProcessUpdateMsg struct{}
 
+// ConfigChangedMsg is sent whenever the config file was modified on disk
+type ConfigChangedMsg struct{}
+
+// eventMsg wraps messages that arrive through the events channel
+type eventMsg struct {
+	msg tea.Msg
+}
+
 // Messages are events that we respond to in our Update function. This
 // particular one indicates that the timer has ticked.
 type tickMsg time.Time
@@ -0,0 +1,64 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/ui"
+)
+
+// wheelLines is the number of lines a notch of the mouse wheel scrolls
+const wheelLines = 3
+
+// handleMouse switches tabs, triggers commands and scrolls with the mouse.
+// what was clicked is worked out by the ui, which knows where it drew what.
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// the mouse leaves inputs and overlays alone
+	if m.InsertMode != types.InsertNone || m.Search.Typing || m.Selection.Prompting || m.Palette.Open || m.ShowHelp {
+		return m, nil
+	}
+
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scrollActive(wheelLines)
+	case tea.MouseButtonWheelDown:
+		m.scrollActive(-wheelLines)
+	case tea.MouseButtonLeft:
+		return m.click(ui.HitTest(m, msg.X, msg.Y))
+	}
+
+	return m, nil
+}
+
+// click does what clicking a tab, command or process does
+func (m *Model) click(target ui.Target) (tea.Model, tea.Cmd) {
+	switch target.Kind {
+	case ui.TargetTab:
+		if target.Index < len(m.Tabs) {
+			m.ActiveTab = target.Index
+			return m.ClearNotification(m.ActiveTab)
+		}
+	case ui.TargetCommand:
+		if target.Index < len(m.Commands) {
+			m.SelectedCommand = target.Index
+			if m.Keys.Trigger.Enabled() {
+				return m, m.do(m.trigger, m.Commands[target.Index], "triggering")
+			}
+		}
+	case ui.TargetProcess:
+		if target.Index < len(m.Processes) {
+			name := m.Processes[target.Index].Shortname
+			for i, tab := range m.Tabs {
+				if tab.Kind == types.TabProcess && tab.Name == name {
+					m.ActiveTab = i
+					return m.ClearNotification(i)
+				}
+			}
+		}
+	}
+
+	return m, nil
+}
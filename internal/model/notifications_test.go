@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/process"
+)
+
+func TestNotificationsCountUnreadLines(t *testing.T) {
+	p := &process.Process{Shortname: "api", Command: "printf 'one\\ntwo\\n'"}
+	m := New([]*process.Process{p}, nil)
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for lines, _ := p.OutputTotals(); lines < 2; lines, _ = p.OutputTotals() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for output")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	counted := m.updateNotifications()()
+	m.Update(counted)
+	if tab := m.GetTabForProcess(p); tab.Unread < 2 || !tab.Notification {
+		t.Fatalf("tab has %d unread lines and notification %v, want at least 2 and a notification", tab.Unread, tab.Notification)
+	}
+
+	// counts taken before the tab was looked at don't bring the lines back
+	m.ClearNotification(2)
+	m.Update(counted)
+	if tab := m.GetTabForProcess(p); tab.Unread != 0 || tab.Notification {
+		t.Errorf("tab has %d unread lines and notification %v after looking at it", tab.Unread, tab.Notification)
+	}
+}
+
+func TestNotificationsLeaveTheTabsToTheLoop(t *testing.T) {
+	p := &process.Process{Shortname: "api", Command: "yes"}
+	m := New([]*process.Process{p}, nil)
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	// the command runs while the loop handles keys and renders, which the
+	// race detector complains about when both touch the tabs
+	counted := make(chan tea.Msg)
+	go func() {
+		for i := 0; i < 20; i++ {
+			counted <- m.updateNotifications()()
+		}
+		close(counted)
+	}()
+
+	for msg := range counted {
+		m.ClearNotification(2)
+		m.View()
+		m.Update(msg)
+	}
+}
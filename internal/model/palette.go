@@ -0,0 +1,207 @@
+package model
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// maxRecent is the number of used palette entries that are remembered
+const maxRecent = 20
+
+// PaletteEntry is something that can be done from the command palette
+type PaletteEntry struct {
+	Title string
+	run   func() (tea.Model, tea.Cmd)
+}
+
+// Palette is the state of the command palette opened with ctrl+p
+type Palette struct {
+	Open bool
+	// Matches are the entries that match the query, best first
+	Matches  []PaletteEntry
+	Selected int
+	entries  []PaletteEntry
+	// recent holds the titles of the entries that were used, most recent
+	// first, so they can be ranked above the rest
+	recent []string
+}
+
+// openPalette collects everything that can be done right now and opens the
+// input to search through it
+func (m *Model) openPalette() tea.Cmd {
+	m.Input = textinput.New()
+	m.Input.Prompt = "> "
+	m.Input.Placeholder = "restart, clear, trigger or go to..."
+
+	m.Palette.Open = true
+	m.Palette.entries = m.paletteEntries()
+	m.filterPalette()
+
+	return m.Input.Focus()
+}
+
+// paletteEntries lists the tabs to go to, the actions on every process and
+// command, and the actions the keys do. the actions are the same ones the
+// keys run, and the ones whose keys are disabled are left out.
+func (m *Model) paletteEntries() []PaletteEntry {
+	k := m.Keys
+	var entries []PaletteEntry
+	add := func(title string, run func() (tea.Model, tea.Cmd)) {
+		entries = append(entries, PaletteEntry{Title: title, run: run})
+	}
+	cmd := func(cmd func() tea.Cmd) func() (tea.Model, tea.Cmd) {
+		return func() (tea.Model, tea.Cmd) { return m, cmd() }
+	}
+	just := func(action func()) func() (tea.Model, tea.Cmd) {
+		return func() (tea.Model, tea.Cmd) {
+			action()
+			return m, nil
+		}
+	}
+
+	for i, tab := range m.Tabs {
+		add("go to "+tab.Name, func() (tea.Model, tea.Cmd) {
+			m.ActiveTab = i
+			return m.ClearNotification(i)
+		})
+	}
+
+	for _, p := range m.Processes {
+		if k.Restart.Enabled() {
+			add("restart "+p.Shortname, cmd(func() tea.Cmd { return m.restartProcess(p) }))
+		}
+		if k.Clear.Enabled() {
+			add("clear "+p.Shortname, cmd(func() tea.Cmd { return m.do(m.clear, p, "clearing") }))
+		}
+		if k.Close.Enabled() {
+			add("close "+p.Shortname, cmd(func() tea.Cmd { return m.closeProcess(p) }))
+		}
+		if k.Grid.Enabled() {
+			add("grid "+p.Shortname, just(func() { m.toggleGrid(p) }))
+		}
+	}
+
+	if k.Trigger.Enabled() {
+		for i, c := range m.Commands {
+			add("trigger "+c.Shortname, func() (tea.Model, tea.Cmd) {
+				m.SelectedCommand = i
+				return m, m.do(m.trigger, c, "triggering")
+			})
+		}
+	}
+
+	add(k.Timestamps.Help().Desc, just(func() { m.Timestamps = (m.Timestamps + 1) % 3 }))
+	add(k.Warnings.Help().Desc, just(m.toggleLevelFilter))
+	add(k.Expand.Help().Desc, just(m.toggleExpanded))
+	add(k.Search.Help().Desc, cmd(m.startSearch))
+	add(k.Select.Help().Desc, just(m.startSelection))
+	add(k.CopyLast.Help().Desc, cmd(m.startCopyPrompt))
+	add(k.CopyAll.Help().Desc, just(func() { m.copyLast(0) }))
+	add(k.Help.Help().Desc, just(func() { m.ShowHelp = true }))
+	add(k.Quit.Help().Desc, cmd(m.Shutdown))
+
+	return entries
+}
+
+// filterPalette keeps the entries that match the query. recently used ones
+// come first, the rest by how well they match.
+func (m *Model) filterPalette() {
+	query := m.Input.Value()
+
+	type match struct {
+		entry  PaletteEntry
+		recent int
+		score  int
+	}
+
+	var matches []match
+	for _, entry := range m.Palette.entries {
+		score, ok := utils.FuzzyMatch(entry.Title, query)
+		if !ok {
+			continue
+		}
+		recent := slices.Index(m.Palette.recent, entry.Title)
+		if recent < 0 {
+			recent = maxRecent
+		}
+		matches = append(matches, match{entry: entry, recent: recent, score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].recent != matches[j].recent {
+			return matches[i].recent < matches[j].recent
+		}
+		return matches[i].score > matches[j].score
+	})
+
+	m.Palette.Matches = make([]PaletteEntry, len(matches))
+	for i, match := range matches {
+		m.Palette.Matches[i] = match.entry
+	}
+	m.Palette.Selected = 0
+}
+
+func (m *Model) closePalette() {
+	m.Palette.Open = false
+	m.Palette.Matches = nil
+	m.Palette.entries = nil
+	m.Input.Blur()
+}
+
+func (m *Model) handlePaletteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.closePalette()
+		return m, nil
+	case "up", "ctrl+p":
+		if n := len(m.Palette.Matches); n > 0 {
+			m.Palette.Selected = (m.Palette.Selected - 1 + n) % n
+		}
+		return m, nil
+	case "down", "ctrl+n", "tab":
+		if n := len(m.Palette.Matches); n > 0 {
+			m.Palette.Selected = (m.Palette.Selected + 1) % n
+		}
+		return m, nil
+	case "enter":
+		if m.Palette.Selected >= len(m.Palette.Matches) {
+			return m, nil
+		}
+		entry := m.Palette.Matches[m.Palette.Selected]
+		m.closePalette()
+		m.rememberPaletteEntry(entry.Title)
+		return entry.run()
+	}
+
+	var cmd tea.Cmd
+	query := m.Input.Value()
+	m.Input, cmd = m.Input.Update(msg)
+	if m.Input.Value() != query {
+		m.filterPalette()
+	}
+	return m, cmd
+}
+
+// rememberPaletteEntry moves a used entry to the front of the recent ones
+func (m *Model) rememberPaletteEntry(title string) {
+	recent := []string{title}
+	for _, t := range m.Palette.recent {
+		if t != title && len(recent) < maxRecent {
+			recent = append(recent, t)
+		}
+	}
+	m.Palette.recent = recent
+}
+
+// paletteTitles returns the titles of the matching entries for the view
+func (m *Model) paletteTitles() []string {
+	titles := make([]string, len(m.Palette.Matches))
+	for i, entry := range m.Palette.Matches {
+		titles[i] = entry.Title
+	}
+	return titles
+}
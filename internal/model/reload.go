@@ -0,0 +1,174 @@
+package model
+
+import (
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/watcher"
+)
+
+// watchConfig starts polling the config file and pushes a ConfigChangedMsg
+// onto the events channel whenever it changes
+func (m *Model) watchConfig() tea.Cmd {
+	return func() tea.Msg {
+		w := watcher.WatchFile(m.ConfigPath, 500*time.Millisecond)
+		for range w.Changes() {
+			m.events <- ConfigChangedMsg{}
+		}
+		return nil
+	}
+}
+
+// reloadConfig loads the config file again and brings the running processes
+// in line with it. processes are matched by shortname, so untouched ones keep
+// running, changed ones are restarted, new ones started and removed ones stopped.
+func (m *Model) reloadConfig() tea.Cmd {
+	cfg, err := config.Load(m.ConfigPath)
+	if err != nil {
+		log.Printf("Error reloading config: %v\n", err)
+		m.ConfigError = err.Error()
+		return nil
+	}
+
+	log.Printf("Reloading config from %s\n", m.ConfigPath)
+	m.ConfigError = ""
+
+	var cmds []tea.Cmd
+
+	existing := make(map[string]*process.Process, len(m.Processes))
+	for _, p := range m.Processes {
+		existing[p.Shortname] = p
+	}
+
+	processes := make([]*process.Process, 0, len(cfg.Processes))
+	for _, pc := range cfg.Processes {
+		if p, ok := existing[pc.Shortname]; ok {
+			delete(existing, pc.Shortname)
+			processes = append(processes, p)
+
+			if p.Apply(pc) {
+				cmds = append(cmds, m.restartProcess(p))
+			}
+			continue
+		}
+
+		added, _ := process.InitializeFromConfig([]config.ProcessConfig{pc})
+		m.track(added[0], false)
+		m.watchTriggers(added[0])
+		processes = append(processes, added[0])
+		cmds = append(cmds, m.startProcess(added[0]))
+	}
+
+	for _, p := range existing {
+		cmds = append(cmds, m.stopRemovedProcess(p))
+	}
+
+	commands := m.reconcileCommands(cfg.Commands)
+
+	m.Mutex.Lock()
+	m.Commands = commands
+	if m.SelectedCommand >= len(m.Commands) {
+		m.SelectedCommand = 0
+	}
+	m.Processes = processes
+	m.Views = cfg.Views
+	m.rebuildTabs()
+	m.CPUWarning, m.MemoryWarning = cfg.CPUWarning, int64(cfg.MemoryWarning)
+	m.SetKeys(cfg.Keys)
+	m.SetAppearance(cfg)
+	m.Mutex.Unlock()
+
+	cmds = append(cmds, m.watchProcesses())
+
+	return tea.Batch(cmds...)
+}
+
+// reconcileCommands keeps the existing command instances around so that
+// recently triggered commands keep showing up in the overview
+func (m *Model) reconcileCommands(configs []config.ProcessConfig) []*process.Process {
+	commands := make([]*process.Process, 0, len(configs))
+	for _, cc := range configs {
+		if c := m.GetCommandByName(cc.Shortname); c != nil {
+			c.Apply(cc)
+			commands = append(commands, c)
+			continue
+		}
+
+		added, _ := process.InitializeFromConfig([]config.ProcessConfig{cc})
+		m.track(added[0], true)
+		commands = append(commands, added[0])
+	}
+
+	return commands
+}
+
+// rebuildTabs updates the tabs in place after the processes or views
+// changed, keeping the state of tabs that still exist and the currently
+// active tab selected
+func (m *Model) rebuildTabs() {
+	active := m.Tabs[m.ActiveTab]
+
+	type tabKey struct {
+		kind types.TabKind
+		name string
+	}
+
+	previous := make(map[tabKey]types.Tab, len(m.Tabs))
+	for _, t := range m.Tabs {
+		previous[tabKey{t.Kind, t.Name}] = t
+	}
+
+	var tabs []types.Tab
+	for _, t := range m.Tabs {
+		if t.Kind == types.TabOverview || t.Kind == types.TabLogs {
+			tabs = append(tabs, t)
+		}
+	}
+
+	for _, p := range m.Processes {
+		tab, ok := previous[tabKey{types.TabProcess, p.Shortname}]
+		if !ok {
+			tab = types.Tab{Name: p.Shortname, Notification: false, Kind: types.TabProcess}
+		}
+		tabs = append(tabs, tab)
+	}
+
+	for _, view := range m.Views {
+		tabs = append(tabs, viewTab(view.Name, view.Processes, view.Columns, previous[tabKey{types.TabView, view.Name}]))
+	}
+
+	// the grid survives a reload, minus the processes that are gone
+	if grid, ok := previous[tabKey{types.TabView, gridTab}]; ok {
+		var names []string
+		for _, pane := range grid.Panes {
+			if m.GetProcessByName(pane.Process) != nil {
+				names = append(names, pane.Process)
+			}
+		}
+		if len(names) > 0 {
+			tabs = append(tabs, viewTab(gridTab, names, grid.Columns, grid))
+		}
+	}
+
+	m.ActiveTab = 0
+	for i, t := range tabs {
+		if t.Kind == active.Kind && t.Name == active.Name {
+			m.ActiveTab = i
+		}
+	}
+
+	m.Tabs = tabs
+}
+
+func (m *Model) stopRemovedProcess(proc *process.Process) tea.Cmd {
+	return func() tea.Msg {
+		if err := proc.Stop(); err != nil {
+			log.Printf("Error stopping removed process %s: %v\n", proc.Shortname, err)
+		}
+		return ProcessUpdateMsg{}
+	}
+}
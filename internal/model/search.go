@@ -0,0 +1,163 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// Search is the state of searching through the output with '/'
+type Search struct {
+	Query string
+	// Typing is set while the query is being typed
+	Typing bool
+	// Seq is the line of the current match
+	Seq    uint64
+	Status string
+}
+
+// startSearch opens the input for a search query in tabs that show output
+func (m *Model) startSearch() tea.Cmd {
+	if m.Tabs[m.ActiveTab].Kind == types.TabOverview {
+		return nil
+	}
+
+	m.Input = textinput.New()
+	m.Input.Prompt = "/"
+	m.Input.SetValue(m.Search.Query)
+	m.Search.Typing = true
+
+	return m.Input.Focus()
+}
+
+func (m *Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.Search.Typing = false
+		m.Input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		m.Search = Search{Query: m.Input.Value()}
+		m.Input.Blur()
+		if m.Search.Query != "" {
+			m.nextMatch(true)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.Input, cmd = m.Input.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) clearSearch() {
+	m.Search = Search{}
+}
+
+// nextMatch scrolls to the next older match of the search, or the next newer
+// one, wrapping around at the ends. the first search starts at the bottom.
+func (m *Model) nextMatch(older bool) {
+	if m.Search.Query == "" {
+		return
+	}
+
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	lines := m.searchLines()
+
+	var matches []int
+	for i, line := range lines {
+		if matchesSearch(line.Text, m.Search.Query) {
+			matches = append(matches, i)
+		}
+	}
+
+	if len(matches) == 0 {
+		m.Search.Seq = 0
+		m.Search.Status = "no matches"
+		return
+	}
+
+	current := -1
+	if m.Search.Seq != 0 {
+		if older {
+			for i := len(matches) - 1; i >= 0; i-- {
+				if lines[matches[i]].Seq < m.Search.Seq {
+					current = i
+					break
+				}
+			}
+		} else {
+			for i, index := range matches {
+				if lines[index].Seq > m.Search.Seq {
+					current = i
+					break
+				}
+			}
+		}
+	}
+
+	if current < 0 {
+		current = len(matches) - 1
+		if m.Search.Seq != 0 && !older {
+			current = 0
+		}
+	}
+
+	index := matches[current]
+	m.Search.Seq = lines[index].Seq
+	m.Search.Status = fmt.Sprintf("%d/%d", len(matches)-current, len(matches))
+
+	// put the match in the middle of the screen rather than at the bottom
+	scroll, counts := m.activeScroll()
+	if scroll == nil || counts == nil {
+		return
+	}
+
+	count, total := counts()
+	scroll.Offset = max(0, min(count, len(lines)-1-index-m.pageSize()/2))
+	scroll.Lines = total
+}
+
+// searchLines returns the lines of the output the active tab shows
+func (m *Model) searchLines() []process.Line {
+	tab := m.Tabs[m.ActiveTab]
+	switch tab.Kind {
+	case types.TabProcess:
+		if proc := m.GetProcessForTab(tab); proc != nil {
+			return proc.TailAtLeast(math.MaxInt32, tab.MinLevel)
+		}
+	case types.TabView:
+		if tab.Focus < len(tab.Panes) {
+			if proc := m.GetProcessByName(tab.Panes[tab.Focus].Process); proc != nil {
+				return proc.Tail(math.MaxInt32)
+			}
+		}
+	case types.TabLogs:
+		var lines []process.Line
+		for _, p := range m.Processes {
+			if !m.Muted[p.Shortname] {
+				lines = append(lines, p.Tail(math.MaxInt32)...)
+			}
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].Seq < lines[j].Seq
+		})
+		return lines
+	}
+	return nil
+}
+
+// matchesSearch reports whether a line of output contains the query,
+// ignoring case and colors
+func matchesSearch(text, query string) bool {
+	return strings.Contains(strings.ToLower(utils.StripAnsi(text)), strings.ToLower(query))
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/session"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+// SetRecorder records the output of all processes and commands to a session,
+// including the ones added by reloading the config later on
+func (m *Model) SetRecorder(recorder *session.Recorder) {
+	m.Recorder = recorder
+	for _, p := range m.Processes {
+		recorder.Track(p, false)
+	}
+	for _, c := range m.Commands {
+		recorder.Track(c, true)
+	}
+}
+
+// track records a process that was added after the session started
+func (m *Model) track(p *process.Process, command bool) {
+	if m.Recorder != nil {
+		m.Recorder.Track(p, command)
+	}
+}
+
+// NewReplay creates a read-only model over the recorded output of a
+// previous session
+func NewReplay(dir string, manifest *session.Manifest) (*Model, error) {
+	load := func(entries []*session.Entry) ([]*process.Process, error) {
+		procs := make([]*process.Process, 0, len(entries))
+		for _, entry := range entries {
+			lines, err := session.ReadLines(dir, entry)
+			if err != nil {
+				return nil, err
+			}
+			procs = append(procs, process.Replayed(entry.Shortname, entry.Command, entry.Description, lines))
+		}
+		return procs, nil
+	}
+
+	processes, err := load(manifest.Processes)
+	if err != nil {
+		return nil, err
+	}
+
+	commands, err := load(manifest.Commands)
+	if err != nil {
+		return nil, err
+	}
+
+	m := New(processes, commands)
+	m.ReadOnly = true
+	m.Keys.DisableProcessKeys()
+	m.Replay = manifest
+	m.Timestamps = types.TimestampsAbsolute
+	m.StartedAt = manifest.StartedAt
+
+	// show how each process ended
+	for _, entry := range manifest.Processes {
+		if tab := m.GetTabForProcess(m.GetProcessByName(entry.Shortname)); tab != nil {
+			tab.Status = runStatus(entry)
+		}
+	}
+
+	return m, nil
+}
+
+// runStatus describes how the last run of a recorded process ended
+func runStatus(entry *session.Entry) string {
+	if len(entry.Runs) == 0 {
+		return "never ran"
+	}
+
+	status := "was running"
+	last := entry.Runs[len(entry.Runs)-1]
+	switch {
+	case last.Stopped:
+		status = "stopped"
+	case last.ExitCode != nil:
+		status = fmt.Sprintf("exit %d", *last.ExitCode)
+	case last.ExitedAt != nil:
+		status = "killed"
+	}
+
+	if restarts := entry.Restarts(); restarts > 0 {
+		status += fmt.Sprintf(", %d×↻", restarts)
+	}
+
+	return status
+}
+
+// replayBanner tells which session is being looked at
+func (m *Model) replayBanner() string {
+	if m.Replay == nil {
+		return ""
+	}
+
+	banner := "replaying session " + m.Replay.ID + " from " + m.Replay.StartedAt.Format("Mon 2 Jan 15:04:05")
+	if m.Replay.EndedAt == nil {
+		banner += ", ren didn't shut down cleanly"
+	}
+	if m.Replay.Truncated {
+		banner += ", output was cut off at the size limit"
+	}
+
+	return banner + " · read-only"
+}
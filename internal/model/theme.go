@@ -0,0 +1,30 @@
+package model
+
+import (
+	"log"
+
+	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/theme"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/ui"
+)
+
+// SetAppearance applies the theme and the layout of the config
+func (m *Model) SetAppearance(cfg *config.Config) {
+	t, err := theme.Load(cfg.Theme, cfg.Themes)
+	if err != nil {
+		log.Printf("Error loading theme: %v\n", err)
+		return
+	}
+
+	ui.SetTheme(t)
+
+	switch cfg.Layout {
+	case "compact":
+		m.Layout = types.LayoutCompact
+	case "sidebar":
+		m.Layout = types.LayoutSidebar
+	default:
+		m.Layout = types.LayoutDefault
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/terminal"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// triggerCooldown is how long a trigger of a process doesn't act again after
+// it acted, so a burst of errors rings the bell once and restarts once
+const triggerCooldown = 5 * time.Second
+
+// TriggerMsg is sent when a line of output matched a trigger
+type TriggerMsg struct {
+	Process *process.Process
+	Trigger config.TriggerConfig
+	Line    process.Line
+}
+
+// watchTriggers forwards the matches of the triggers of a process onto the
+// events channel. a process that keeps printing what matched only gets its
+// trigger through once per cooldown, rather than once per line.
+func (m *Model) watchTriggers(p *process.Process) {
+	p.OnMatch = func(p *process.Process, trigger config.TriggerConfig, line process.Line) {
+		if m.coolingDown(p, trigger) {
+			return
+		}
+		m.post(TriggerMsg{Process: p, Trigger: trigger, Line: line})
+	}
+}
+
+// coolingDown reports whether the trigger of a process acted less than
+// triggerCooldown ago, and otherwise remembers that it acts now
+func (m *Model) coolingDown(proc *process.Process, trigger config.TriggerConfig) bool {
+	m.triggeredMutex.Lock()
+	defer m.triggeredMutex.Unlock()
+
+	key := proc.Shortname + "\x00" + trigger.Pattern
+	if time.Since(m.triggered[key]) < triggerCooldown {
+		return true
+	}
+	m.triggered[key] = time.Now()
+
+	return false
+}
+
+// runTrigger does what the trigger that matched is configured to do
+func (m *Model) runTrigger(msg TriggerMsg) tea.Cmd {
+	proc, trigger := msg.Process, msg.Trigger
+	text := utils.StripAnsi(msg.Line.Text)
+	var cmds []tea.Cmd
+
+	for _, action := range trigger.Actions {
+		switch action {
+		case "mark":
+			if tab := m.GetTabForProcess(proc); tab != nil && m.GetActiveTabName() != tab.Name &&
+				process.SeverityRank(trigger.Severity) > process.SeverityRank(tab.Alert) {
+				tab.Alert = trigger.Severity
+			}
+		case "bell":
+			terminal.Bell()
+		case "notify":
+			cmds = append(cmds, func() tea.Msg {
+				if err := terminal.Notify("ren: "+proc.Shortname, text); err != nil {
+					log.Printf("Error notifying about %s: %v\n", proc.Shortname, err)
+				}
+				return nil
+			})
+		case "restart":
+			if proc.IsStopped() {
+				continue
+			}
+			cmds = append(cmds, func() tea.Msg {
+				if err := proc.RestartBecause(fmt.Sprintf("%q matched", trigger.Pattern)); err != nil {
+					log.Printf("Error restarting process %s: %v\n", proc.Shortname, err)
+				}
+				return ProcessUpdateMsg{}
+			})
+		}
+	}
+
+	if trigger.Run != "" {
+		cmds = append(cmds, m.runTriggerCommand(proc, trigger, text))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// runTriggerCommand runs the command of a trigger in the directory of the
+// process, telling it what matched through the environment
+func (m *Model) runTriggerCommand(proc *process.Process, trigger config.TriggerConfig, text string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := process.ShellCommand(trigger.Run)
+		cmd.Dir = proc.Cwd
+		cmd.Env = append(cmd.Environ(),
+			"REN_PROCESS="+proc.Shortname,
+			"REN_SEVERITY="+trigger.Severity,
+			"REN_LINE="+text,
+		)
+
+		if output, err := cmd.CombinedOutput(); err != nil {
+			log.Printf("Error running trigger of %s: %v: %s\n", proc.Shortname, err, output)
+		}
+		return nil
+	}
+}
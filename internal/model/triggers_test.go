@@ -0,0 +1,40 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/process"
+)
+
+func TestTriggerMatchesQueueOncePerCooldown(t *testing.T) {
+	p := &process.Process{Shortname: "api"}
+	m := New([]*process.Process{p}, nil)
+
+	// a busy loop that hasn't taken anything yet
+	for i := 0; i < cap(m.events); i++ {
+		m.events <- tickMsg{}
+	}
+
+	trigger := config.TriggerConfig{Pattern: "panic", Actions: []string{"bell"}}
+	for i := 0; i < 1000; i++ {
+		p.OnMatch(p, trigger, process.Line{Text: "panic: oh no"})
+	}
+
+	m.pendingMutex.Lock()
+	pending := len(m.pending)
+	m.pendingMutex.Unlock()
+	if pending != 1 {
+		t.Fatalf("%d messages are waiting for the loop, want 1", pending)
+	}
+
+	// the match comes after everything that was posted before it
+	for i := 0; i < cap(m.events); i++ {
+		if _, ok := (<-m.events).(tickMsg); !ok {
+			t.Fatalf("message %d isn't the tick that was posted first", i)
+		}
+	}
+	if msg, ok := (<-m.events).(TriggerMsg); !ok || msg.Trigger.Pattern != "panic" {
+		t.Fatalf("got %#v, want the trigger", msg)
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/procstat"
+)
+
+// usageInterval is how often the CPU and memory use of processes is sampled
+const usageInterval = 2 * time.Second
+
+// sampleUsage keeps measuring the CPU and memory use of the processes and
+// commands for the overview, on systems where that can be read
+func (m *Model) sampleUsage() tea.Cmd {
+	if !procstat.Supported {
+		return nil
+	}
+
+	return func() tea.Msg {
+		for range time.Tick(usageInterval) {
+			// one scan of the system serves every process, and the metrics
+			// read the samples instead of scanning again
+			snapshot, err := procstat.Scan()
+			if err != nil {
+				continue
+			}
+			now := time.Now()
+
+			processes, commands := m.Snapshot()
+			for _, p := range processes {
+				p.SampleUsage(snapshot, now)
+			}
+			for _, c := range commands {
+				c.SampleUsage(snapshot, now)
+			}
+		}
+		return nil
+	}
+}
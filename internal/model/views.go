@@ -0,0 +1,232 @@
+package model
+
+import (
+	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/loglevel"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+// gridTab is the name of the view tab processes are added to with 'g'
+const gridTab = "grid"
+
+// SetViews adds a tab for each of the configured views
+func (m *Model) SetViews(views []config.ViewConfig) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	m.Views = views
+	m.rebuildTabs()
+}
+
+// viewTab creates the tab for a view, reusing the scroll positions of the
+// panes that were there before
+func viewTab(name string, processes []string, columns int, previous types.Tab) types.Tab {
+	scrolls := make(map[string]types.Scroll, len(previous.Panes))
+	for _, pane := range previous.Panes {
+		scrolls[pane.Process] = pane.Scroll
+	}
+
+	tab := types.Tab{Name: name, Kind: types.TabView, Columns: columns, Focus: previous.Focus}
+	for _, p := range processes {
+		tab.Panes = append(tab.Panes, types.Pane{Process: p, Scroll: scrolls[p]})
+	}
+
+	if tab.Focus >= len(tab.Panes) {
+		tab.Focus = 0
+	}
+
+	return tab
+}
+
+// toggleGrid adds the process to the grid tab or removes it from there. the
+// grid tab comes and goes with the processes in it.
+func (m *Model) toggleGrid(proc *process.Process) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	index := -1
+	for i, t := range m.Tabs {
+		if t.Kind == types.TabView && t.Name == gridTab {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		m.Tabs = append(m.Tabs, types.Tab{Name: gridTab, Kind: types.TabView})
+		index = len(m.Tabs) - 1
+	}
+
+	grid := &m.Tabs[index]
+	for i, pane := range grid.Panes {
+		if pane.Process == proc.Shortname {
+			grid.Panes = append(grid.Panes[:i], grid.Panes[i+1:]...)
+			if grid.Focus >= len(grid.Panes) {
+				grid.Focus = 0
+			}
+
+			if len(grid.Panes) == 0 {
+				m.Tabs = append(m.Tabs[:index], m.Tabs[index+1:]...)
+				if m.ActiveTab >= len(m.Tabs) {
+					m.ActiveTab = 0
+				}
+			}
+			return
+		}
+	}
+
+	grid.Panes = append(grid.Panes, types.Pane{Process: proc.Shortname})
+}
+
+// focusPane moves the focus in the active view tab
+func (m *Model) focusPane(delta int) {
+	tab := &m.Tabs[m.ActiveTab]
+	if len(tab.Panes) == 0 {
+		return
+	}
+	tab.Focus = (tab.Focus + delta + len(tab.Panes)) % len(tab.Panes)
+}
+
+// activeScroll returns the scroll position of the active tab, or of the
+// focused pane in a view tab, together with a func that counts its lines
+func (m *Model) activeScroll() (*types.Scroll, func() (int, int)) {
+	tab := &m.Tabs[m.ActiveTab]
+	switch tab.Kind {
+	case types.TabProcess:
+		return &tab.Scroll, processLineCounts(m.GetProcessForTab(*tab), tab.MinLevel)
+	case types.TabLogs:
+		return &tab.Scroll, m.logsLineCounts
+	case types.TabView:
+		if tab.Focus < len(tab.Panes) {
+			pane := &tab.Panes[tab.Focus]
+			return &pane.Scroll, processLineCounts(m.GetProcessByName(pane.Process), loglevel.Unknown)
+		}
+	}
+	return nil, nil
+}
+
+// processLineCounts returns the number of lines a process holds and the
+// number of lines it wrote in total, of those with at least the given level
+func processLineCounts(proc *process.Process, min loglevel.Level) func() (int, int) {
+	if proc == nil {
+		return nil
+	}
+	return func() (int, int) {
+		if min != loglevel.Unknown {
+			return proc.LineCountsAtLeast(min)
+		}
+		return proc.LineCount(), proc.TotalLines()
+	}
+}
+
+// toggleLevelFilter switches the active process tab between showing all
+// output and only warnings and worse
+func (m *Model) toggleLevelFilter() {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	tab := &m.Tabs[m.ActiveTab]
+	if tab.Kind != types.TabProcess {
+		return
+	}
+
+	if tab.MinLevel == loglevel.Unknown {
+		tab.MinLevel = loglevel.Warn
+	} else {
+		tab.MinLevel = loglevel.Unknown
+	}
+
+	// the offset counts different lines now, so start at the bottom again
+	tab.Scroll = types.Scroll{}
+}
+
+// toggleExpanded shows the JSON line at the cursor as the whole indented
+// object, or collapses it again. the cursor is the selected line, the
+// current search match or else the bottom line on screen, and the closest
+// JSON line at or above it is the one expanded.
+func (m *Model) toggleExpanded() {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	scroll, _ := m.activeScroll()
+	lines := m.searchLines()
+	if scroll == nil || len(lines) == 0 {
+		return
+	}
+
+	cursor := lines[max(0, len(lines)-1-scroll.Offset)].Seq
+	switch {
+	case m.Selection.Active:
+		cursor = m.Selection.Cursor
+	case m.Search.Seq != 0:
+		cursor = m.Search.Seq
+	}
+
+	tab := &m.Tabs[m.ActiveTab]
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].Seq > cursor || !lines[i].JSON {
+			continue
+		}
+		if tab.ExpandedSeq == lines[i].Seq {
+			tab.ExpandedSeq = 0
+		} else {
+			tab.ExpandedSeq = lines[i].Seq
+		}
+		return
+	}
+}
+
+// scrollActive scrolls the active output up by the given number of lines,
+// or down when negative. scrolling all the way down follows the output again.
+func (m *Model) scrollActive(lines int) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	scroll, counts := m.activeScroll()
+	if scroll == nil || counts == nil {
+		return
+	}
+
+	count, total := counts()
+	scroll.Offset = max(0, min(count, scroll.Offset+lines))
+	scroll.Lines = total
+}
+
+// pageSize is the number of lines pgup and pgdown scroll
+func (m *Model) pageSize() int {
+	return max(1, m.WindowSize.Height/2)
+}
+
+// followOutput keeps scrolled outputs in place while new lines come in
+func (m *Model) followOutput() {
+	keep := func(scroll *types.Scroll, counts func() (int, int)) {
+		if counts == nil || scroll.Following() {
+			return
+		}
+
+		_, total := counts()
+		if total < scroll.Lines {
+			// the output was cleared, so there's nothing to stay at
+			*scroll = types.Scroll{}
+			return
+		}
+
+		scroll.Offset += total - scroll.Lines
+		scroll.Lines = total
+	}
+
+	for i := range m.Tabs {
+		tab := &m.Tabs[i]
+		switch tab.Kind {
+		case types.TabProcess:
+			keep(&tab.Scroll, processLineCounts(m.GetProcessForTab(*tab), tab.MinLevel))
+		case types.TabLogs:
+			keep(&tab.Scroll, m.logsLineCounts)
+		case types.TabView:
+			for j := range tab.Panes {
+				keep(&tab.Panes[j].Scroll, processLineCounts(m.GetProcessByName(tab.Panes[j].Process), loglevel.Unknown))
+			}
+		}
+	}
+}
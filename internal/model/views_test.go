@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/process"
+)
+
+func TestExpandOnlyTheJSONLineAtTheCursor(t *testing.T) {
+	p := &process.Process{Shortname: "api", Command: `printf '{"msg":"one"}\n{"msg":"two"}\nplain\n'`}
+	m := New([]*process.Process{p}, nil)
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for lines, _ := p.OutputTotals(); lines < 3; lines, _ = p.OutputTotals() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for output")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	m.ActiveTab = 2
+	lines := m.searchLines()
+	one, two := lines[len(lines)-3], lines[len(lines)-2]
+
+	// the bottom line isn't JSON, so the one above it is expanded
+	m.toggleExpanded()
+	if got := m.Tabs[m.ActiveTab].ExpandedSeq; got != two.Seq {
+		t.Fatalf("expanded line %d, want %d", got, two.Seq)
+	}
+
+	// with a selection the line at its cursor is expanded instead
+	m.Selection = Selection{Active: true, Anchor: one.Seq, Cursor: one.Seq}
+	m.toggleExpanded()
+	if got := m.Tabs[m.ActiveTab].ExpandedSeq; got != one.Seq {
+		t.Fatalf("expanded line %d, want %d", got, one.Seq)
+	}
+
+	// and expanding it again collapses it
+	m.toggleExpanded()
+	if got := m.Tabs[m.ActiveTab].ExpandedSeq; got != 0 {
+		t.Errorf("expanded line %d after collapsing it", got)
+	}
+}
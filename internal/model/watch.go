@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/watcher"
+)
+
+// FileChangedMsg is sent when a watched file of a process changed
+type FileChangedMsg struct {
+	Process *process.Process
+	Event   watcher.Event
+}
+
+// watchProcesses starts a file watcher for every process that has one
+// configured, replacing the watchers that were running before
+func (m *Model) watchProcesses() tea.Cmd {
+	m.closeWatchers()
+
+	m.watchMutex.Lock()
+	defer m.watchMutex.Unlock()
+
+	var cmds []tea.Cmd
+	for _, p := range m.Processes {
+		if p.Watch == nil {
+			continue
+		}
+
+		w, err := watcher.New(watcher.Options{
+			Dir:      p.Watch.Dir,
+			Paths:    p.Watch.Paths,
+			Include:  p.Watch.Include,
+			Exclude:  p.Watch.Exclude,
+			Debounce: time.Duration(p.Watch.Debounce),
+		})
+		if err != nil {
+			log.Printf("Error watching files for %s: %v\n", p.Shortname, err)
+			continue
+		}
+
+		m.watchers = append(m.watchers, w)
+		cmds = append(cmds, m.forwardFileChanges(p, w))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) closeWatchers() {
+	m.watchMutex.Lock()
+	defer m.watchMutex.Unlock()
+
+	for _, w := range m.watchers {
+		w.Close()
+	}
+	m.watchers = nil
+}
+
+// forwardFileChanges pushes the events of the watcher onto the events
+// channel until the watcher is closed
+func (m *Model) forwardFileChanges(p *process.Process, w *watcher.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		for event := range w.Events() {
+			m.events <- FileChangedMsg{Process: p, Event: event}
+		}
+		return nil
+	}
+}
+
+// restartForChange restarts the process after one of its watched files
+// changed, unless it has been stopped on purpose
+func (m *Model) restartForChange(msg FileChangedMsg) tea.Cmd {
+	proc := msg.Process
+	if proc.IsStopped() {
+		return nil
+	}
+
+	reason := fmt.Sprintf("%s changed", msg.Event.Path)
+	if msg.Event.Count > 1 {
+		reason = fmt.Sprintf("%s and %d other files changed", msg.Event.Path, msg.Event.Count-1)
+	}
+
+	return func() tea.Msg {
+		log.Printf("Restarting %s: %s\n", proc.Shortname, reason)
+		if err := proc.RestartBecause(reason); err != nil {
+			log.Printf("Error restarting process %s: %v\n", proc.Shortname, err)
+		}
+		return ProcessUpdateMsg{}
+	}
+}
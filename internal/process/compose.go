@@ -0,0 +1,124 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// TypeCompose marks a process that runs a docker compose service
+const TypeCompose = "compose"
+
+// healthInterval is how often the container state of compose services is checked
+const healthInterval = 2 * time.Second
+
+// IsCompose reports whether the process runs a docker compose service
+func (p *Process) IsCompose() bool {
+	return p.Type == TypeCompose
+}
+
+// Health returns the last known container state of a compose service, like
+// "running", "healthy" or "unhealthy". it's empty for other processes.
+func (p *Process) Health() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.health
+}
+
+// composeStop stops the service through docker, after which the attached
+// `docker compose up` that runs as up exits by itself
+func (p *Process) composeStop(up *exec.Cmd) error {
+	cmd := exec.Command("docker", "compose", "stop", p.Service)
+	cmd.Dir = up.Dir
+	cmd.Env = up.Env
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// pollHealth keeps the health of the service that up runs up to date until
+// done is closed
+func (p *Process) pollHealth(up *exec.Cmd, done chan struct{}) {
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			p.mutex.Lock()
+			p.health = ""
+			p.mutex.Unlock()
+			return
+		case <-ticker.C:
+			health := p.composeHealth(up)
+			p.mutex.Lock()
+			p.health = health
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// composeContainer holds the fields of `docker compose ps --format json` we need
+type composeContainer struct {
+	Service string `json:"Service"`
+	State   string `json:"State"`
+	Health  string `json:"Health"`
+}
+
+// composeHealth asks docker about the service that up runs, in the same
+// directory and environment so it finds the same project
+func (p *Process) composeHealth(up *exec.Cmd) string {
+	cmd := exec.Command("docker", "compose", "ps", "--all", "--format", "json", p.Service)
+	cmd.Dir = up.Dir
+	cmd.Env = up.Env
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "unknown"
+	}
+
+	containers := parseComposePs(output)
+	if len(containers) == 0 {
+		return "starting"
+	}
+
+	// the health check is more telling than the state, if there is one
+	container := containers[0]
+	if container.Health != "" {
+		return container.Health
+	}
+
+	return container.State
+}
+
+// parseComposePs handles both the json array older docker versions print
+// and the json object per line newer ones do
+func parseComposePs(output []byte) []composeContainer {
+	output = bytes.TrimSpace(output)
+
+	var containers []composeContainer
+	if bytes.HasPrefix(output, []byte("[")) {
+		if err := json.Unmarshal(output, &containers); err != nil {
+			return nil
+		}
+		return containers
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		var container composeContainer
+		if err := json.Unmarshal(scanner.Bytes(), &container); err == nil {
+			containers = append(containers, container)
+		}
+	}
+
+	return containers
+}
@@ -0,0 +1,135 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thejawker/rennen/internal/testutil"
+)
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func composeProcess() *Process {
+	return &Process{Shortname: "db", Type: TypeCompose, Service: "postgres"}
+}
+
+func TestComposeStartRunsUp(t *testing.T) {
+	dir := testutil.FakeDocker(t)
+	p := composeProcess()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	waitFor(t, "docker compose up", func() bool {
+		return testutil.DockerCalls(t, dir)[0] != ""
+	})
+
+	if got := testutil.DockerCalls(t, dir)[0]; got != "compose up postgres" {
+		t.Errorf("started %q, want compose up postgres", got)
+	}
+	if p.IsStopped() {
+		t.Errorf("stopped right after starting")
+	}
+}
+
+func TestComposeStopRunsStop(t *testing.T) {
+	dir := testutil.FakeDocker(t)
+	p := composeProcess()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// up exits once the service is stopped, well before the force kill
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("stopping took %s", elapsed)
+	}
+
+	if calls := testutil.DockerCalls(t, dir); !slices.Contains(calls, "compose stop postgres") {
+		t.Errorf("docker was called with %q, want compose stop postgres", calls)
+	}
+	if !p.IsStopped() {
+		t.Errorf("not stopped after stopping")
+	}
+}
+
+func TestComposeHealth(t *testing.T) {
+	dir := testutil.FakeDocker(t)
+	p := composeProcess()
+	up := exec.Command("docker", "compose", "up", p.Service)
+
+	for _, tt := range []struct {
+		ps   string
+		want string
+	}{
+		{`{"Service":"postgres","State":"running","Health":"healthy"}`, "healthy"},
+		{`{"Service":"postgres","State":"running","Health":""}`, "running"},
+		{`[{"Service":"postgres","State":"exited","Health":""}]`, "exited"},
+		{``, "starting"},
+	} {
+		testutil.SetDockerPs(t, dir, tt.ps)
+		if got := p.composeHealth(up); got != tt.want {
+			t.Errorf("health of %s is %q, want %q", tt.ps, got, tt.want)
+		}
+	}
+}
+
+func TestComposeStopFallsBackToSignal(t *testing.T) {
+	dir := testutil.FakeDocker(t)
+	p := composeProcess()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a docker that can't stop the service leaves up to be signalled
+	testutil.WriteDocker(t, dir, "#!/bin/sh\ncase \"$2\" in\nup) exec sleep 30 ;;\nstop) exit 1 ;;\nesac\n")
+
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if code, exited := p.LastExitCode(); !exited || code == 0 {
+		t.Errorf("up exited with %d (exited %v), want it to be signalled", code, exited)
+	}
+
+	if err := p.Restart(); err != nil {
+		t.Fatalf("restarting after a failed compose stop: %v", err)
+	}
+	p.Stop()
+}
+
+func TestComposeHealthUsesTheProjectOfUp(t *testing.T) {
+	dir := testutil.FakeDocker(t)
+	p := composeProcess()
+
+	// the service only exists in the project up was started in
+	testutil.WriteDocker(t, dir, "#!/bin/sh\n[ \"$COMPOSE_PROJECT_NAME\" = shop ] && cat \"$FAKE_DOCKER/ps\"\n")
+	testutil.SetDockerPs(t, dir, `{"Service":"postgres","State":"running","Health":"healthy"}`)
+
+	up := exec.Command("docker", "compose", "up", p.Service)
+	up.Dir = t.TempDir()
+	up.Env = append(os.Environ(), "COMPOSE_PROJECT_NAME=shop")
+
+	if got := p.composeHealth(up); got != "healthy" {
+		t.Errorf("health is %q, want healthy", got)
+	}
+}
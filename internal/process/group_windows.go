@@ -0,0 +1,20 @@
+//go:build windows
+
+package process
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup does nothing on windows, where there are no process groups
+// to signal
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalGroup signals just the process itself, since windows can only kill
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	if sig == syscall.SIGKILL {
+		return cmd.Process.Kill()
+	}
+	return cmd.Process.Signal(sig)
+}
@@ -0,0 +1,53 @@
+package process
+
+import (
+	"log"
+
+	"github.com/thejawker/rennen/internal/logging"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// writeLog appends a finished line to the log file of the process, opening
+// it on first use. must be called with the mutex held.
+func (p *Process) writeLog(line Line) {
+	if p.Log == nil || p.logFailed {
+		return
+	}
+
+	if p.logFile == nil {
+		file, err := logging.OpenRotating(p.Log.Path, p.Log.MaxSize, p.Log.MaxFiles)
+		if err != nil {
+			// don't try again for every line, a reload or restart resets this
+			log.Printf("process %s can't write its log: %v", p.Shortname, err)
+			p.logFailed = true
+			return
+		}
+		p.logFile = file
+	}
+
+	text := line.Text
+	if !p.Log.ANSI {
+		text = utils.StripAnsi(text)
+	}
+	if line.Stream == StreamRen {
+		text = "[ren] " + text
+	}
+
+	if _, err := p.logFile.Write([]byte(text + "\n")); err != nil {
+		log.Printf("process %s failed to write its log: %v", p.Shortname, err)
+	}
+}
+
+// closeLog closes the log file, it's opened again with the next line
+func (p *Process) closeLog() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.logFile != nil {
+		if err := p.logFile.Close(); err != nil {
+			log.Printf("process %s failed to close its log: %v", p.Shortname, err)
+		}
+		p.logFile = nil
+	}
+	p.logFailed = false
+}
@@ -0,0 +1,49 @@
+package process
+
+import (
+	"time"
+
+	"github.com/thejawker/rennen/internal/loglevel"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// Observer is told about everything that happens to a process, for example
+// to record a session. it's called with the process locked, so it must not
+// call back into the process.
+type Observer interface {
+	Started(p *Process, at time.Time)
+	Output(p *Process, line Line)
+	Exited(p *Process, code int, stopped bool, at time.Time)
+}
+
+// Replayed creates a process that never runs and only holds output that was
+// recorded before, for looking at a previous session
+func Replayed(shortname, command, description string, lines []Line) *Process {
+	p := &Process{
+		Shortname:   shortname,
+		Command:     command,
+		Description: description,
+		stopped:     true,
+		exitCode:    -1,
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	for i := range lines {
+		if lines[i].Stream != StreamRen {
+			lines[i].Level = loglevel.Detect(lines[i].Text)
+			lines[i].JSON = utils.FindJSONObject(lines[i].Text, false) >= 0
+		}
+		p.levelTotals[lines[i].Level]++
+	}
+
+	p.lines = lines
+	p.totalLines = len(lines)
+
+	if len(lines) > 0 {
+		p.LastActivity = lines[len(lines)-1].Time
+	}
+
+	return p
+}
@@ -0,0 +1,320 @@
+package process
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/thejawker/rennen/internal/loglevel"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+// maxLines is the number of lines kept per process, older ones are dropped
+const maxLines = 10000
+
+// sequence orders lines across all processes by when they arrived
+var sequence atomic.Uint64
+
+// Stream tells where a line of output came from
+type Stream int
+
+const (
+	StreamStdout Stream = iota
+	StreamStderr
+	// StreamRen is for the lines ren writes itself, like restart markers
+	StreamRen
+)
+
+func (s Stream) String() string {
+	switch s {
+	case StreamStderr:
+		return "stderr"
+	case StreamRen:
+		return "ren"
+	}
+	return "stdout"
+}
+
+// Line is a single line of output
+type Line struct {
+	// Seq increases with every line of every process, so it can be used to
+	// interleave the output of several processes
+	Seq    uint64
+	Time   time.Time
+	Stream Stream
+	Text   string
+	// Level is the log level of the line, if it has one
+	Level loglevel.Level
+	// JSON is set when the line holds a JSON object to be pretty-printed
+	JSON bool
+}
+
+// AtLeast tells whether the line has at least the given level
+func (l Line) AtLeast(min loglevel.Level) bool {
+	return l.Level >= min
+}
+
+// streamWriter receives the output of one of the streams of a process
+type streamWriter struct {
+	process *Process
+	stream  Stream
+}
+
+func (w *streamWriter) Write(data []byte) (int, error) {
+	w.process.mutex.Lock()
+	defer w.process.mutex.Unlock()
+
+	w.process.stats.bytes += uint64(len(data))
+	w.process.appendOutput(w.stream, utils.StripTerminalReturns(string(data)))
+
+	return len(data), nil
+}
+
+// appendOutput splits a chunk of output into lines. the last line of each
+// stream is kept apart until its newline comes in. must be called with the
+// mutex held.
+func (p *Process) appendOutput(stream Stream, chunk string) {
+	now := time.Now()
+	p.LastActivity = now
+
+	if p.partials == nil {
+		p.partials = make(map[Stream]Line)
+	}
+
+	// a line is stamped with the time it started coming in
+	partial, ok := p.partials[stream]
+	if !ok {
+		partial = Line{Time: now, Stream: stream}
+	}
+
+	parts := strings.Split(partial.Text+chunk, "\n")
+	for _, part := range parts[:len(parts)-1] {
+		partial.Seq = sequence.Add(1)
+		partial.Text = part
+		p.addLine(partial)
+		partial.Time = now
+	}
+
+	partial.Text = parts[len(parts)-1]
+	if partial.Text == "" {
+		delete(p.partials, stream)
+	} else {
+		p.partials[stream] = partial
+	}
+}
+
+// isError tells whether a line is an error. without a level the stream
+// decides.
+func isError(line Line) bool {
+	if line.Level == loglevel.Unknown {
+		return line.Stream == StreamStderr
+	}
+	return line.Level >= loglevel.Error
+}
+
+// flushPartials finishes the lines that are still waiting for a newline.
+// must be called with the mutex held.
+func (p *Process) flushPartials() {
+	for _, stream := range []Stream{StreamStdout, StreamStderr} {
+		if _, ok := p.partials[stream]; ok {
+			p.appendOutput(stream, "\n")
+		}
+	}
+}
+
+// appendLine adds a line of ren's own, like a restart marker, finishing any
+// partial lines first. must be called with the mutex held.
+func (p *Process) appendLine(text string) {
+	p.flushPartials()
+	p.addLine(Line{Seq: sequence.Add(1), Time: time.Now(), Stream: StreamRen, Text: text})
+}
+
+func (p *Process) addLine(line Line) {
+	if line.Stream != StreamRen {
+		line.Level = loglevel.Detect(line.Text)
+		line.JSON = p.Format != "text" && utils.FindJSONObject(line.Text, p.Format == "json") >= 0
+	}
+
+	p.writeLog(line)
+	if p.Observer != nil {
+		p.Observer.Output(p, line)
+	}
+	p.matchTriggers(line)
+
+	if line.Stream != StreamRen {
+		p.stats.lines++
+		if isError(line) {
+			p.stats.errors++
+		}
+	}
+	p.levelTotals[line.Level]++
+
+	p.lines = append(p.lines, line)
+	p.totalLines++
+
+	// trim in batches so that not every line has to move all the others
+	if len(p.lines) > maxLines+maxLines/10 {
+		p.lines = append(p.lines[:0], p.lines[len(p.lines)-maxLines:]...)
+	}
+}
+
+// pending returns the lines that are still being written, as lines
+func (p *Process) pending() []Line {
+	var lines []Line
+	for _, stream := range []Stream{StreamStdout, StreamStderr} {
+		if partial, ok := p.partials[stream]; ok {
+			partial.Seq = sequence.Load()
+			partial.Level = loglevel.Detect(partial.Text)
+			lines = append(lines, partial)
+		}
+	}
+	return lines
+}
+
+// GetOutput returns the current output of the process
+func (p *Process) GetOutput() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var b strings.Builder
+	for _, lines := range [][]Line{p.lines, p.pending()} {
+		for _, line := range lines {
+			b.WriteString(line.Text)
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Tail returns up to n of the most recent lines, including lines that are
+// still being written
+func (p *Process) Tail(n int) []Line {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	lines := p.lines
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	tail := make([]Line, len(lines))
+	copy(tail, lines)
+	tail = append(tail, p.pending()...)
+
+	if len(tail) > n {
+		tail = tail[len(tail)-n:]
+	}
+
+	return tail
+}
+
+// TailAtLeast returns up to n of the most recent lines that have at least
+// the given level, including lines that are still being written
+func (p *Process) TailAtLeast(n int, min loglevel.Level) []Line {
+	if min == loglevel.Unknown {
+		return p.Tail(n)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var tail []Line
+	pending := p.pending()
+	for i := len(pending) - 1; i >= 0 && len(tail) < n; i-- {
+		if pending[i].AtLeast(min) {
+			tail = append(tail, pending[i])
+		}
+	}
+	for i := len(p.lines) - 1; i >= 0 && len(tail) < n; i-- {
+		if p.lines[i].AtLeast(min) {
+			tail = append(tail, p.lines[i])
+		}
+	}
+
+	slices.Reverse(tail)
+	return tail
+}
+
+// Since returns up to n of the most recent finished lines that came in after
+// the line with the given sequence number
+func (p *Process) Since(seq uint64, n int) []Line {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	start := len(p.lines)
+	for start > 0 && len(p.lines)-start < n && p.lines[start-1].Seq > seq {
+		start--
+	}
+
+	since := make([]Line, len(p.lines)-start)
+	copy(since, p.lines[start:])
+
+	return since
+}
+
+// LineCount returns the number of lines in the output
+func (p *Process) LineCount() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.lines)
+}
+
+// LineCountsAtLeast returns the number of lines with at least the given
+// level that the output holds, and that were written since it was last
+// cleared
+func (p *Process) LineCountsAtLeast(min loglevel.Level) (count, total int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, line := range p.lines {
+		if line.AtLeast(min) {
+			count++
+		}
+	}
+	for level, n := range p.levelTotals {
+		if loglevel.Level(level) >= min {
+			total += n
+		}
+	}
+
+	return count, total
+}
+
+// TotalLines returns the number of lines written since the output was last
+// cleared, including the ones that were dropped to save memory
+func (p *Process) TotalLines() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.totalLines
+}
+
+func (p *Process) ClearOutput() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.clearOutput()
+}
+
+func (p *Process) clearOutput() {
+	p.lines = nil
+	p.partials = nil
+	p.totalLines = 0
+	p.levelTotals = [loglevel.Fatal + 1]int{}
+	p.triggerMatches = nil
+}
+
+func (p *Process) GetLastNonEmptyLine() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, lines := range [][]Line{p.pending(), p.lines} {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if len(lines[i].Text) > 0 {
+				return lines[i].Text
+			}
+		}
+	}
+
+	return ""
+}
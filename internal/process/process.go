@@ -2,9 +2,9 @@ package process
 
 import (
 	"fmt"
-	"github.com/thejawker/rennen/internal/utils"
 	"io"
 	"log"
+	"maps"
 	"os"
 	"os/exec"
 	"runtime"
@@ -14,20 +14,69 @@ import (
 	"time"
 
 	"github.com/thejawker/rennen/internal/config"
+	"github.com/thejawker/rennen/internal/logging"
+	"github.com/thejawker/rennen/internal/loglevel"
 )
 
+// waitDelay is how long to keep reading output after the process exited
+const waitDelay = 2 * time.Second
+
 // Process represents a running process
 type Process struct {
 	Shortname    string
 	Command      string
 	Description  string
-	Output       string
+	Type         string
+	Service      string
+	Cwd          string
+	Env          map[string]string
+	Watch        *config.WatchConfig
+	Log          *config.LogConfig
+	lines        []Line
+	partials     map[Stream]Line
+	totalLines   int
 	Cmd          *exec.Cmd
 	LastActivity time.Time
-	StartedAt    *time.Time
-	mutex        sync.Mutex
-	done         chan struct{}
-	stopped      bool
+	// Format tells which lines are JSON to be pretty-printed: "auto", "json"
+	// or "text"
+	Format string
+	// Notify is when the tab of the process shows there's output you haven't
+	// seen: "any", "errors" or "never"
+	Notify    string
+	StartedAt *time.Time
+	mutex     sync.Mutex
+	done      chan struct{}
+	exited    chan struct{}
+	exitCode  int
+	stopped   bool
+	health    string
+	stdin     io.WriteCloser
+	logFile   *logging.RotatingFile
+	logFailed bool
+	Observer  Observer
+	stats     stats
+	usage     usageTracker
+	// levelTotals counts the lines written since the output was last
+	// cleared by their log level
+	levelTotals [loglevel.Fatal + 1]int
+
+	// Triggers act on lines of output that match their pattern, and OnMatch
+	// is called for every match. it's called with the process locked, like
+	// the Observer.
+	Triggers       []config.TriggerConfig
+	OnMatch        func(p *Process, trigger config.TriggerConfig, line Line)
+	triggerMatches []int
+}
+
+// stats are counters that keep going for as long as the process exists,
+// regardless of restarts or the output being cleared
+type stats struct {
+	starts       int
+	lines        uint64
+	errors       uint64
+	bytes        uint64
+	lastExitCode int
+	hasExited    bool
 }
 
 // InitializeFromConfig creates Process instances from the provided configuration
@@ -38,18 +87,101 @@ func InitializeFromConfig(configs []config.ProcessConfig) ([]*Process, error) {
 			Shortname:   cfg.Shortname,
 			Command:     cfg.Command,
 			Description: cfg.Description,
+			Type:        cfg.Type,
+			Service:     cfg.Service,
+			Cwd:         cfg.Cwd,
+			Env:         cfg.Env,
+			Watch:       cfg.Watch,
+			Log:         cfg.Log,
+			Triggers:    cfg.Triggers,
+			Notify:      cfg.Notify,
+			Format:      cfg.Format,
 		}
 	}
 	return processes, nil
 }
 
+// ShellCommand runs a command line the way processes are run, through zsh
+// when that's the user's shell and otherwise sh, or cmd on windows
+func ShellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	if shell := os.Getenv("SHELL"); strings.Contains(shell, "zsh") {
+		return exec.Command("zsh", "-c", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// Apply updates the process with a newly loaded configuration and reports
+// whether anything changed that requires a restart
+func (p *Process) Apply(cfg config.ProcessConfig) bool {
+	if !sameLog(p.Log, cfg.Log) {
+		// the next line opens the new file
+		p.closeLog()
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.Description = cfg.Description
+	p.Watch = cfg.Watch
+	p.Log = cfg.Log
+
+	// the counts belong to the patterns, so they start over when those change
+	if !sameTriggers(p.Triggers, cfg.Triggers) {
+		p.triggerMatches = nil
+	}
+	p.Triggers = cfg.Triggers
+	p.Notify = cfg.Notify
+	p.Format = cfg.Format
+
+	if p.Command == cfg.Command && p.Cwd == cfg.Cwd && maps.Equal(p.Env, cfg.Env) &&
+		p.Type == cfg.Type && p.Service == cfg.Service {
+		return false
+	}
+
+	p.Type = cfg.Type
+	p.Service = cfg.Service
+	p.Command = cfg.Command
+	p.Cwd = cfg.Cwd
+	p.Env = cfg.Env
+
+	return true
+}
+
+func sameLog(a, b *config.LogConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Restart stops the process, clears its output and starts it again
 func (p *Process) Restart() error {
+	return p.restart(func() {
+		p.clearOutput()
+		p.appendLine("restarted process...")
+	})
+}
+
+// RestartBecause restarts the process while keeping its output, and writes a
+// marker line with the reason for the restart into it
+func (p *Process) RestartBecause(reason string) error {
+	return p.restart(func() {
+		p.appendLine("restarted: " + reason)
+	})
+}
+
+func (p *Process) restart(mark func()) error {
 	if err := p.Stop(); err != nil {
 		return fmt.Errorf("failed to stop process: %w", err)
 	}
 
+	p.mutex.Lock()
 	p.stopped = false
-	p.Output = "restarted process...\n"
+	mark()
+	p.mutex.Unlock()
 
 	if err := p.Start(); err != nil {
 		return fmt.Errorf("failed to start process: %w", err)
@@ -74,12 +206,10 @@ func (p *Process) Start() error {
 
 	var cmd *exec.Cmd
 
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/C", p.Command)
-	} else if shell := os.Getenv("SHELL"); strings.Contains(shell, "zsh") {
-		cmd = exec.Command("zsh", "-c", p.Command)
+	if p.IsCompose() {
+		cmd = exec.Command("docker", "compose", "up", p.Service)
 	} else {
-		cmd = exec.Command("sh", "-c", p.Command)
+		cmd = ShellCommand(p.Command)
 	}
 
 	// force color
@@ -89,58 +219,159 @@ func (p *Process) Start() error {
 		"FORCE_COLOR=true",
 	)
 
-	p.Cmd = cmd
-
-	stdout, err := p.Cmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	for key, value := range p.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
 	}
 
-	stderr, err := p.Cmd.StderrPipe()
+	cmd.Dir = p.Cwd
+
+	// stdout and stderr are read concurrently by exec, line by line
+	cmd.Stdout = &streamWriter{process: p, stream: StreamStdout}
+	cmd.Stderr = &streamWriter{process: p, stream: StreamStderr}
+
+	// don't hang on to the output forever when a child outlives the process
+	cmd.WaitDelay = waitDelay
+	setProcessGroup(cmd)
+
+	p.Cmd = cmd
+
+	var err error
+	p.stdin, err = p.Cmd.StdinPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
 
 	if err := p.Cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start process: %w", err)
 	}
 
-	go p.handleOutput(io.MultiReader(stdout, stderr))
+	p.exited = make(chan struct{})
+	p.exitCode = -1
+	p.stats.starts++
+	go p.wait(cmd, p.exited)
+
+	if p.Observer != nil {
+		p.Observer.Started(p, now)
+	}
+
+	if p.IsCompose() {
+		go p.pollHealth(cmd, p.done)
+	}
 
 	return nil
 }
 
-// handleOutput reads the process output and updates the Process struct
-func (p *Process) handleOutput(reader io.Reader) {
-	buffer := make([]byte, 1024)
-	for {
-		select {
-		case <-p.done:
-			return
-		default:
-			n, err := reader.Read(buffer)
-			if n > 0 {
-				p.mutex.Lock()
-				p.Output += utils.StripTerminalReturns(string(buffer[:n]))
-				p.LastActivity = time.Now()
-				p.mutex.Unlock()
-			}
-			if err != nil {
-				if err == io.EOF {
-					return
-				}
-				log.Printf("error reading process output: %v", err)
-				return
-			}
-		}
+// wait reaps the process once it exits and remembers its exit code
+func (p *Process) wait(cmd *exec.Cmd, exited chan struct{}) {
+	err := cmd.Wait()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	defer close(exited)
+
+	p.exitCode = cmd.ProcessState.ExitCode()
+	p.stats.lastExitCode = p.exitCode
+	p.stats.hasExited = true
+	if err != nil && !p.stopped {
+		log.Printf("process %s exited: %v", p.Shortname, err)
 	}
+
+	// flush whatever is left without a trailing newline
+	p.flushPartials()
+
+	if !p.stopped && p.exitCode != 0 {
+		p.appendLine(fmt.Sprintf("process exited with code %d", p.exitCode))
+	}
+
+	if p.Observer != nil {
+		p.Observer.Exited(p, p.exitCode, p.stopped, time.Now())
+	}
+}
+
+// ExitCode returns the exit code of the last run, or -1 while it's running
+// or when it was killed by a signal
+func (p *Process) ExitCode() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.exitCode
 }
 
-// GetOutput returns the current output of the process
-func (p *Process) GetOutput() string {
+// Restarts returns how often the process was started again after its first start
+func (p *Process) Restarts() int {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	return p.Output
+	return max(0, p.stats.starts-1)
+}
+
+// LastExitCode returns the exit code of the most recent run that ended, and
+// false when no run has ended yet
+func (p *Process) LastExitCode() (int, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.stats.lastExitCode, p.stats.hasExited
+}
+
+// OutputTotals returns the number of lines and bytes the process wrote
+// over all of its runs
+func (p *Process) OutputTotals() (lines, bytes uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.stats.lines, p.stats.bytes
+}
+
+// ErrorTotal returns the number of lines over all runs that were errors,
+// going by their log level or otherwise by them being written to stderr
+func (p *Process) ErrorTotal() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.stats.errors
+}
+
+// StartTime returns when the current run started, or nil when the process
+// was stopped
+func (p *Process) StartTime() *time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.StartedAt
+}
+
+// Uptime returns how long the current run has been going, or 0
+func (p *Process) Uptime() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.StartedAt == nil || p.exited == nil || isClosed(p.exited) {
+		return 0
+	}
+	return time.Since(*p.StartedAt)
+}
+
+// Pid returns the process id of the running process, or 0
+func (p *Process) Pid() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Cmd == nil || p.Cmd.Process == nil || p.exited == nil || isClosed(p.exited) {
+		return 0
+	}
+	return p.Cmd.Process.Pid
+}
+
+// State describes whether the process is running: "idle" when it never
+// started, "running", "exited" when it ended by itself or "stopped"
+func (p *Process) State() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch {
+	case p.stopped:
+		return "stopped"
+	case p.exited == nil:
+		return "idle"
+	case isClosed(p.exited):
+		return "exited"
+	}
+	return "running"
 }
 
 // IsActive checks if the process has had activity in the last minute
@@ -150,60 +381,104 @@ func (p *Process) IsActive() bool {
 	return time.Since(p.LastActivity) < time.Minute
 }
 
+// Write sends input to the stdin of the running process
+func (p *Process) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	stdin, stopped := p.stdin, p.stopped
+	p.mutex.Unlock()
+
+	if stdin == nil || stopped {
+		return 0, fmt.Errorf("process is not running")
+	}
+
+	return stdin.Write(data)
+}
+
+// Interrupt sends an interrupt to the process and everything it started,
+// like pressing ctrl+c in a terminal would
+func (p *Process) Interrupt() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Cmd == nil || p.Cmd.Process == nil || p.stopped {
+		return fmt.Errorf("process is not running")
+	}
+
+	return signalGroup(p.Cmd, syscall.SIGINT)
+}
+
 // Stop gracefully stops the process
 func (p *Process) Stop() error {
+	// closed after the last markers are written
+	defer p.closeLog()
+
 	p.mutex.Lock()
-	defer p.mutex.Unlock()
 
 	p.StartedAt = nil
 
 	if p.stopped {
+		p.mutex.Unlock()
 		return nil
 	}
 
 	p.stopped = true
-	close(p.done)
+	if p.done != nil {
+		close(p.done)
+	}
+
+	cmd, exited := p.Cmd, p.exited
+	if cmd == nil || cmd.Process == nil || exited == nil || isClosed(exited) {
+		p.mutex.Unlock()
+		return nil
+	}
+
+	p.appendLine("Stopping process")
 
-	if p.Cmd != nil && p.Cmd.Process != nil {
-		// Send SIGTERM
-		err := p.Cmd.Process.Signal(syscall.SIGTERM)
-		if err != nil {
+	// the output keeps coming in while we wait, so the mutex can't be held
+	p.mutex.Unlock()
+
+	// stopping the attached `up` would leave the container running, so
+	// compose services are stopped through docker unless that fails
+	signal := true
+	if p.IsCompose() {
+		if err := p.composeStop(cmd); err != nil {
+			log.Printf("Error stopping compose service %s, stopping docker compose up instead: %v\n", p.Service, err)
+		} else {
+			signal = false
+		}
+	}
+
+	// the shell's children get the signal too, so they don't hold on to the
+	// output or outlive the process
+	if signal {
+		if err := signalGroup(cmd, syscall.SIGTERM); err != nil && !isClosed(exited) {
 			return fmt.Errorf("failed to send SIGTERM: %w", err)
 		}
+	}
 
-		p.Output += "\nStopping process\n"
-
-		// Wait for the process to exit or force kill after timeout
-		done := make(chan error, 1)
-		go func() {
-			_, err := p.Cmd.Process.Wait()
-			done <- err
-		}()
-
-		select {
-		case <-time.After(5 * time.Second):
-			// Force kill if it doesn't exit within 5 seconds
-			err = p.Cmd.Process.Kill()
-			if err != nil {
-				return fmt.Errorf("failed to kill process: %w", err)
-			}
-		case err := <-done:
-			if err != nil {
-				return fmt.Errorf("process exited with error: %w", err)
-			}
-
-			p.Output += "\nProcess stopped gracefully\n"
-			// Process exited gracefully
+	select {
+	case <-time.After(5 * time.Second):
+		// Force kill if it doesn't exit within 5 seconds
+		if err := signalGroup(cmd, syscall.SIGKILL); err != nil && !isClosed(exited) {
+			return fmt.Errorf("failed to kill process: %w", err)
 		}
+		<-exited
+	case <-exited:
+		p.mutex.Lock()
+		p.appendLine("Process stopped gracefully")
+		p.mutex.Unlock()
 	}
 
 	return nil
 }
 
-func (p *Process) ClearOutput() {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	p.Output = ""
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
 }
 
 func (p *Process) IsStopped() bool {
@@ -211,16 +486,3 @@ func (p *Process) IsStopped() bool {
 	defer p.mutex.Unlock()
 	return p.stopped
 }
-
-func (p *Process) GetLastNonEmptyLine() string {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	lines := strings.Split(p.Output, "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		if len(lines[i]) > 0 {
-			return lines[i]
-		}
-	}
-
-	return ""
-}
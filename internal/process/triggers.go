@@ -0,0 +1,61 @@
+package process
+
+import (
+	"slices"
+
+	"github.com/thejawker/rennen/internal/config"
+)
+
+// matchTriggers counts the triggers the line matches and tells OnMatch about
+// them. ren's own lines never match.
+func (p *Process) matchTriggers(line Line) {
+	if line.Stream == StreamRen || len(p.Triggers) == 0 {
+		return
+	}
+
+	if len(p.triggerMatches) != len(p.Triggers) {
+		p.triggerMatches = make([]int, len(p.Triggers))
+	}
+
+	for i, trigger := range p.Triggers {
+		if trigger.Regexp == nil || !trigger.Regexp.MatchString(line.Text) {
+			continue
+		}
+
+		p.triggerMatches[i]++
+		if p.OnMatch != nil {
+			p.OnMatch(p, trigger, line)
+		}
+	}
+}
+
+// TriggerMatches returns how many lines matched a trigger since the output
+// was last cleared, and the highest severity among those triggers
+func (p *Process) TriggerMatches() (count int, severity string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, n := range p.triggerMatches {
+		if n == 0 || i >= len(p.Triggers) {
+			continue
+		}
+
+		count += n
+		if SeverityRank(p.Triggers[i].Severity) > SeverityRank(severity) {
+			severity = p.Triggers[i].Severity
+		}
+	}
+
+	return count, severity
+}
+
+// SeverityRank orders severities from low to high, with no severity lowest
+func SeverityRank(severity string) int {
+	return slices.Index(config.Severities, severity) + 1
+}
+
+func sameTriggers(a, b []config.TriggerConfig) bool {
+	return slices.EqualFunc(a, b, func(x, y config.TriggerConfig) bool {
+		return x.Pattern == y.Pattern && x.Severity == y.Severity
+	})
+}
@@ -0,0 +1,95 @@
+package process
+
+import (
+	"time"
+
+	"github.com/thejawker/rennen/internal/procstat"
+)
+
+// usageSamples is the number of usage samples kept per process
+const usageSamples = 12
+
+// UsageSample is the CPU and memory use of a process and its descendants
+// at a point in time
+type UsageSample struct {
+	Time time.Time
+	// CPU is the percentage of a single core used since the previous sample
+	CPU float64
+	RSS uint64
+}
+
+// usageTracker remembers the previous sample to turn CPU time into a percentage
+type usageTracker struct {
+	samples []UsageSample
+	pid     int
+	cpu     time.Duration
+	at      time.Time
+	// total is the CPU time used over all runs. it never goes down, not even
+	// when a child that nobody waited for leaves the tree.
+	total time.Duration
+}
+
+// SampleUsage measures the CPU and memory use of the running process in a
+// snapshot of the system. a process that isn't running keeps its last
+// samples.
+func (p *Process) SampleUsage(snapshot *procstat.Snapshot, now time.Time) {
+	pid := p.Pid()
+	if pid == 0 {
+		return
+	}
+
+	// reading memory from /proc takes a moment, so don't hold the lock meanwhile
+	usage, err := snapshot.Tree(pid)
+	if err != nil {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	tracker := &p.usage
+	sample := UsageSample{Time: now, RSS: usage.RSS}
+
+	// the first sample of a run has nothing to compare the CPU time to
+	used := usage.CPU
+	if tracker.pid == pid {
+		used = max(0, usage.CPU-tracker.cpu)
+		if now.After(tracker.at) {
+			sample.CPU = float64(used) / float64(now.Sub(tracker.at)) * 100
+		}
+	}
+	tracker.total += used
+
+	tracker.pid, tracker.cpu, tracker.at = pid, usage.CPU, now
+	tracker.samples = append(tracker.samples, sample)
+	if len(tracker.samples) > usageSamples {
+		tracker.samples = tracker.samples[len(tracker.samples)-usageSamples:]
+	}
+}
+
+// UsageTotals returns the CPU time used over all runs and the memory the
+// current run uses, as of the last sample
+func (p *Process) UsageTotals() (cpu time.Duration, rss uint64, ok bool) {
+	pid := p.Pid()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	tracker := &p.usage
+	if pid == 0 || tracker.pid != pid || len(tracker.samples) == 0 {
+		return 0, 0, false
+	}
+
+	return tracker.total, tracker.samples[len(tracker.samples)-1].RSS, true
+}
+
+// Usage returns the most recent usage samples, oldest first
+func (p *Process) Usage() []UsageSample {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	samples := make([]UsageSample, len(p.usage.samples))
+	copy(samples, p.usage.samples)
+
+	return samples
+}
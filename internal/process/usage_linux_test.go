@@ -0,0 +1,39 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thejawker/rennen/internal/procstat"
+)
+
+func TestUsageKeepsTheCPUTimeOfExitedChildren(t *testing.T) {
+	// a child that keeps a core busy for a moment and exits, while the
+	// process itself lives on
+	p := &Process{Shortname: "build", Command: `sh -c 'i=0; while [ $i -lt 200000 ]; do i=$((i+1)); done'; sleep 30`}
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	var highest time.Duration
+	for i := 0; i < 25; i++ {
+		snapshot, err := procstat.Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.SampleUsage(snapshot, time.Now())
+
+		if cpu, _, ok := p.UsageTotals(); ok {
+			if cpu < highest {
+				t.Fatalf("the CPU time went down from %s to %s", highest, cpu)
+			}
+			highest = cpu
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if highest < 100*time.Millisecond {
+		t.Errorf("used %s of CPU time, want the time the child was busy", highest)
+	}
+}
@@ -0,0 +1,21 @@
+package procstat
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned on systems where usage can't be read
+var ErrUnsupported = errors.New("reading process usage isn't supported on this system")
+
+// Usage is what a process and all of its descendants use together. a
+// process is usually a shell, so its own numbers would say very little.
+type Usage struct {
+	// CPU is the CPU time used so far, user and system together, including
+	// that of children that already exited
+	CPU time.Duration
+	// RSS is the resident memory in bytes
+	RSS uint64
+	// Processes is the number of processes in the tree
+	Processes int
+}
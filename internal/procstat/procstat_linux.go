@@ -0,0 +1,123 @@
+//go:build linux
+
+package procstat
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is USER_HZ, which is 100 on every architecture Linux runs on
+const clockTicks = 100
+
+type stat struct {
+	ppid int
+	cpu  time.Duration
+}
+
+// Supported tells whether usage can be read on this system
+const Supported = true
+
+// Snapshot is every process on the system at one point in time. reading
+// /proc takes a while, so it's read once and used for every process.
+type Snapshot struct {
+	stats    map[int]stat
+	children map[int][]int
+}
+
+// Scan reads the parent and CPU time of every process from /proc
+func Scan() (*Snapshot, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{stats: make(map[int]stat), children: make(map[int][]int)}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// processes come and go while we look, so skip the ones that went
+		st, err := readStat(pid)
+		if err != nil {
+			continue
+		}
+		s.stats[pid] = st
+		s.children[st.ppid] = append(s.children[st.ppid], pid)
+	}
+
+	return s, nil
+}
+
+// Tree returns the usage of the process with the given pid and everything
+// it started
+func (s *Snapshot) Tree(pid int) (Usage, error) {
+	root, ok := s.stats[pid]
+	if !ok {
+		return Usage{}, fmt.Errorf("process %d isn't running", pid)
+	}
+
+	usage := Usage{CPU: root.cpu, RSS: readRSS(pid), Processes: 1}
+	queue := s.children[pid]
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = append(queue[1:], s.children[child]...)
+
+		usage.CPU += s.stats[child].cpu
+		usage.RSS += readRSS(child)
+		usage.Processes++
+	}
+
+	return usage, nil
+}
+
+// readStat reads the parent and the CPU time from /proc/<pid>/stat
+func readStat(pid int) (stat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return stat{}, err
+	}
+
+	// the name in parentheses can contain spaces, so start after it
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return stat{}, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 15 {
+		return stat{}, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	// children that exited and were waited for leave their time behind here
+	cutime, _ := strconv.ParseUint(fields[13], 10, 64)
+	cstime, _ := strconv.ParseUint(fields[14], 10, 64)
+
+	return stat{
+		ppid: ppid,
+		cpu:  time.Duration(utime+stime+cutime+cstime) * time.Second / clockTicks,
+	}, nil
+}
+
+// readRSS reads the resident memory in bytes from /proc/<pid>/statm
+func readRSS(pid int) uint64 {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err != nil {
+		return 0
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+
+	pages, _ := strconv.ParseUint(fields[1], 10, 64)
+	return pages * uint64(os.Getpagesize())
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package procstat
+
+// Supported tells whether usage can be read on this system
+const Supported = false
+
+// Snapshot is every process on the system at one point in time
+type Snapshot struct{}
+
+// Scan reads every process on the system
+func Scan() (*Snapshot, error) {
+	return nil, ErrUnsupported
+}
+
+// Tree returns the usage of the process with the given pid and everything
+// it started
+func (s *Snapshot) Tree(pid int) (Usage, error) {
+	return Usage{}, ErrUnsupported
+}
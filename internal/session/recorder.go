@@ -0,0 +1,297 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/thejawker/rennen/internal/process"
+)
+
+// Recorder writes the output of every process it tracks to a new session
+// directory, together with a manifest of when they ran
+type Recorder struct {
+	Dir      string
+	mutex    sync.Mutex
+	manifest Manifest
+	entries  map[*process.Process]*recording
+	// size is how much output was recorded so far
+	size   int64
+	done   chan struct{}
+	closed bool
+}
+
+type recording struct {
+	entry  *Entry
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// Start creates a new session in root and removes the oldest sessions so
+// that they don't pile up
+func Start(root, configPath string) (*Recorder, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	now := time.Now()
+	id := now.Format("20060102-150405")
+
+	// two sessions started within the same second get a suffix
+	dir := filepath.Join(root, id)
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0o755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create session directory: %w", err)
+		}
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), i)
+		dir = filepath.Join(root, id)
+	}
+
+	r := &Recorder{
+		Dir:      dir,
+		manifest: Manifest{ID: id, Config: configPath, StartedAt: now},
+		entries:  make(map[*process.Process]*recording),
+		done:     make(chan struct{}),
+	}
+
+	if err := r.writeManifest(); err != nil {
+		return nil, err
+	}
+
+	prune(root)
+
+	go r.flushLoop()
+
+	return r, nil
+}
+
+// flushLoop writes the buffered output to disk every flushInterval, until
+// the recorder is closed
+func (r *Recorder) flushLoop() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.mutex.Lock()
+			r.flush()
+			r.mutex.Unlock()
+		}
+	}
+}
+
+// flush writes the buffered output of every process to disk. must be called
+// with the mutex held.
+func (r *Recorder) flush() {
+	for p, rec := range r.entries {
+		if rec.writer == nil {
+			continue
+		}
+		if err := rec.writer.Flush(); err != nil {
+			log.Printf("Error recording %s: %v\n", p.Shortname, err)
+		}
+	}
+}
+
+// prune removes all but the most recent sessions
+func prune(root string) {
+	ids, err := List(root)
+	if err != nil || len(ids) <= keep {
+		return
+	}
+
+	for _, id := range ids[:len(ids)-keep] {
+		if err := os.RemoveAll(filepath.Join(root, id)); err != nil {
+			log.Printf("Error removing old session %s: %v\n", id, err)
+		}
+	}
+}
+
+// Track starts recording a process or command
+func (r *Recorder) Track(p *process.Process, command bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.entries[p]; ok {
+		return
+	}
+
+	kind := "process"
+	if command {
+		kind = "command"
+	}
+
+	entry := &Entry{
+		Shortname:   p.Shortname,
+		Command:     p.Command,
+		Description: p.Description,
+		File:        fileName(kind, p.Shortname),
+	}
+
+	if command {
+		r.manifest.Commands = append(r.manifest.Commands, entry)
+	} else {
+		r.manifest.Processes = append(r.manifest.Processes, entry)
+	}
+
+	r.entries[p] = &recording{entry: entry}
+	p.Observer = r
+}
+
+func (r *Recorder) Started(p *process.Process, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rec, ok := r.entries[p]
+	if !ok {
+		return
+	}
+
+	// the command may have changed with a reload
+	rec.entry.Command = p.Command
+	rec.entry.Runs = append(rec.entry.Runs, Run{StartedAt: at})
+
+	if err := r.writeManifest(); err != nil {
+		log.Printf("Error recording session: %v\n", err)
+	}
+}
+
+func (r *Recorder) Output(p *process.Process, line process.Line) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rec, ok := r.entries[p]
+	if !ok || r.closed || r.manifest.Truncated {
+		return
+	}
+
+	if rec.file == nil {
+		file, err := os.OpenFile(filepath.Join(r.Dir, rec.entry.File), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			log.Printf("Error recording %s: %v\n", p.Shortname, err)
+			return
+		}
+		rec.file = file
+		rec.writer = bufio.NewWriter(file)
+	}
+
+	data, err := json.Marshal(record{Seq: line.Seq, Time: line.Time, Stream: line.Stream.String(), Text: line.Text})
+	if err != nil {
+		return
+	}
+
+	if r.size+int64(len(data))+1 > maxSize {
+		log.Printf("Session %s recorded %d bytes of output, not recording any more\n", r.manifest.ID, r.size)
+		r.manifest.Truncated = true
+		if err := r.writeManifest(); err != nil {
+			log.Printf("Error recording session: %v\n", err)
+		}
+		return
+	}
+	r.size += int64(len(data)) + 1
+
+	if _, err := rec.writer.Write(append(data, '\n')); err != nil {
+		log.Printf("Error recording %s: %v\n", p.Shortname, err)
+	}
+
+	// errors are what a replay is looked at for, so they don't wait in the
+	// buffer for a crash to lose them
+	if line.Stream == process.StreamStderr {
+		if err := rec.writer.Flush(); err != nil {
+			log.Printf("Error recording %s: %v\n", p.Shortname, err)
+		}
+	}
+}
+
+func (r *Recorder) Exited(p *process.Process, code int, stopped bool, at time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rec, ok := r.entries[p]
+	if !ok || len(rec.entry.Runs) == 0 {
+		return
+	}
+
+	if rec.writer != nil {
+		if err := rec.writer.Flush(); err != nil {
+			log.Printf("Error recording %s: %v\n", p.Shortname, err)
+		}
+	}
+
+	run := &rec.entry.Runs[len(rec.entry.Runs)-1]
+	run.ExitedAt = &at
+	run.Stopped = stopped
+	if code >= 0 {
+		run.ExitCode = &code
+	}
+
+	if err := r.writeManifest(); err != nil {
+		log.Printf("Error recording session: %v\n", err)
+	}
+}
+
+// Flush writes the output recorded so far to disk, for when ren is about to
+// go away without getting to Close
+func (r *Recorder) Flush() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.flush()
+}
+
+// Close finishes the session
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+
+	r.flush()
+	for _, rec := range r.entries {
+		if rec.file != nil {
+			rec.file.Close()
+			rec.file = nil
+			rec.writer = nil
+		}
+	}
+
+	now := time.Now()
+	r.manifest.EndedAt = &now
+
+	return r.writeManifest()
+}
+
+// writeManifest replaces the manifest in one go, so a crash never leaves
+// half of it behind. must be called with the mutex held.
+func (r *Recorder) writeManifest() error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.manifest); err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.Dir, "manifest.json")
+	if err := os.WriteFile(path+".tmp", data.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write session manifest: %w", err)
+	}
+
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thejawker/rennen/internal/process"
+)
+
+func TestRecordingReplays(t *testing.T) {
+	root := t.TempDir()
+	r, err := Start(root, "ren.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &process.Process{Shortname: "web", Command: "npm run dev"}
+	r.Track(p, false)
+
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	r.Started(p, start)
+
+	lines := []process.Line{
+		{Seq: 1, Time: start, Stream: process.StreamStdout, Text: "listening on :3000"},
+		{Seq: 2, Time: start.Add(time.Second), Stream: process.StreamStderr, Text: "TypeError: x is undefined"},
+		{Seq: 3, Time: start.Add(2 * time.Second), Stream: process.StreamRen, Text: "Process exited with code 1"},
+	}
+	for _, line := range lines[:2] {
+		r.Output(p, line)
+	}
+
+	// an error is on disk right away, in case ren doesn't live to flush it
+	manifest, err := Open(r.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := ReadLines(r.Dir, manifest.Processes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 2 {
+		t.Fatalf("recorded %d lines before flushing, want the 2 up to the error", len(recorded))
+	}
+
+	r.Output(p, lines[2])
+	r.Exited(p, 1, false, start.Add(3*time.Second))
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := List(root)
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("listed sessions %v (%v), want one", ids, err)
+	}
+
+	dir := filepath.Join(root, ids[0])
+	manifest, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.EndedAt == nil || manifest.Truncated {
+		t.Errorf("manifest ended at %v and truncated %v, want it ended and whole", manifest.EndedAt, manifest.Truncated)
+	}
+
+	entry := manifest.Processes[0]
+	if entry.Shortname != "web" || entry.Command != "npm run dev" || len(entry.Runs) != 1 {
+		t.Fatalf("recorded %+v", entry)
+	}
+	if run := entry.Runs[0]; !run.StartedAt.Equal(start) || run.ExitCode == nil || *run.ExitCode != 1 || run.Stopped {
+		t.Errorf("recorded run %+v, want one that exited with 1", run)
+	}
+
+	replayed, err := ReadLines(dir, entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != len(lines) {
+		t.Fatalf("replayed %d lines, want %d", len(replayed), len(lines))
+	}
+	for i, line := range replayed {
+		want := lines[i]
+		if line.Seq != want.Seq || !line.Time.Equal(want.Time) || line.Stream != want.Stream || line.Text != want.Text {
+			t.Errorf("line %d replayed as %+v, want %+v", i, line, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/thejawker/rennen/internal/process"
+)
+
+// keep is the number of sessions kept around, older ones are removed when
+// a new session starts
+const keep = 20
+
+// maxSize is how much output a session records over all processes. output
+// past it is left out, so a chatty process can't fill up the disk.
+const maxSize = 256 << 20
+
+// flushInterval is how often recorded output is written to disk. output is
+// buffered in between, so recording doesn't slow down the processes.
+const flushInterval = time.Second
+
+// Manifest describes a recorded session. it's rewritten whenever a process
+// starts or exits, so it's useful even when ren didn't get to shut down.
+type Manifest struct {
+	ID        string     `json:"id"`
+	Config    string     `json:"config"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Processes []*Entry   `json:"processes"`
+	Commands  []*Entry   `json:"commands"`
+	// Truncated is set when the output grew past maxSize and the rest of
+	// it wasn't recorded
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Entry is a process or command of a session and every time it ran
+type Entry struct {
+	Shortname   string `json:"shortname"`
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	File        string `json:"file"`
+	Runs        []Run  `json:"runs"`
+}
+
+// Restarts is the number of times the process was started again
+func (e *Entry) Restarts() int {
+	return max(0, len(e.Runs)-1)
+}
+
+// Run is a single run of a process
+type Run struct {
+	StartedAt time.Time  `json:"started_at"`
+	ExitedAt  *time.Time `json:"exited_at,omitempty"`
+	ExitCode  *int       `json:"exit_code,omitempty"`
+	// Stopped is set when ren stopped the process, rather than it exiting
+	// by itself
+	Stopped bool `json:"stopped,omitempty"`
+}
+
+// record is a line of output as it's written to disk
+type record struct {
+	Seq    uint64    `json:"seq"`
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"`
+	Text   string    `json:"text"`
+}
+
+// Root is the directory the sessions of the project are recorded in
+func Root(configDir string) string {
+	return filepath.Join(configDir, ".ren", "sessions")
+}
+
+// List returns the ids of the recorded sessions, oldest first
+func List(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			ids = append(ids, entry.Name())
+		}
+	}
+
+	// ids start with the time, so they sort chronologically
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// Open reads the manifest of a recorded session
+func Open(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse session manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// ReadLines reads back the recorded output of a process. a line that was
+// cut off halfway by a crash is skipped.
+func ReadLines(dir string, entry *Entry) ([]process.Line, error) {
+	file, err := os.Open(filepath.Join(dir, entry.File))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []process.Line
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		lines = append(lines, process.Line{Seq: r.Seq, Time: r.Time, Stream: parseStream(r.Stream), Text: r.Text})
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseStream(s string) process.Stream {
+	switch s {
+	case "stderr":
+		return process.StreamStderr
+	case "ren":
+		return process.StreamRen
+	}
+	return process.StreamStdout
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName is the name of the file the output of a process is recorded in
+func fileName(kind, shortname string) string {
+	return kind + "-" + unsafeChars.ReplaceAllString(shortname, "_") + ".jsonl"
+}
@@ -0,0 +1,103 @@
+package terminal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Output is the terminal bubbletea renders to. escape sequences are written
+// through it too, and since bubbletea writes every frame in one go, they never
+// end up in the middle of one.
+var Output = &output{file: os.Stdout}
+
+// output serializes the writes to a terminal. it has the methods of a file
+// bubbletea needs to see that it renders to a terminal.
+type output struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.file.Write(p)
+}
+
+func (o *output) WriteString(s string) (int, error) {
+	return o.Write([]byte(s))
+}
+
+func (o *output) Read(p []byte) (int, error) {
+	return o.file.Read(p)
+}
+
+func (o *output) Close() error {
+	return o.file.Close()
+}
+
+func (o *output) Fd() uintptr {
+	return o.file.Fd()
+}
+
+func write(s string) {
+	io.WriteString(Output, s)
+}
+
+// Bell rings the terminal bell
+func Bell() {
+	write("\a")
+}
+
+// Notify shows a desktop notification. it uses notify-send when that's
+// installed, and otherwise asks the terminal to show one with OSC 777 or
+// OSC 9, depending on which of the two the terminal is likely to know.
+func Notify(title, body string) error {
+	if path, err := exec.LookPath("notify-send"); err == nil {
+		return exec.Command(path, "--app-name=ren", title, body).Run()
+	}
+
+	title, body = sanitize(title), sanitize(body)
+	if supportsOSC777() {
+		write(fmt.Sprintf("\x1b]777;notify;%s;%s\x1b\\", title, body))
+	} else {
+		write(fmt.Sprintf("\x1b]9;%s: %s\x1b\\", title, body))
+	}
+
+	return nil
+}
+
+// supportsOSC777 tells whether the terminal is one of those that show
+// notifications for OSC 777, which are the VTE based ones, foot and urxvt
+func supportsOSC777() bool {
+	if os.Getenv("VTE_VERSION") != "" {
+		return true
+	}
+	term := os.Getenv("TERM")
+	return strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "rxvt")
+}
+
+// sanitize removes what would end the escape sequence early or split its
+// fields
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// Copy puts text on the clipboard with OSC 52, which also works over SSH.
+// inside tmux the sequence is passed through to the terminal around it.
+func Copy(text string) {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if os.Getenv("TMUX") != "" {
+		sequence = "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	write(sequence)
+}
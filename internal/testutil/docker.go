@@ -0,0 +1,74 @@
+// Package testutil has the fixtures tests of several packages share
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeDocker answers like docker compose does. it writes its arguments to
+// calls, `up` keeps running until `stop` is called like an attached
+// `docker compose up` does, and `ps` prints the file ps.
+const fakeDocker = `#!/bin/sh
+echo "$@" >> "$FAKE_DOCKER/calls"
+case "$2" in
+up) while [ ! -f "$FAKE_DOCKER/stopped" ]; do sleep 0.05; done ;;
+stop) touch "$FAKE_DOCKER/stopped" ;;
+ps) cat "$FAKE_DOCKER/ps" ;;
+esac
+`
+
+// FakeDocker puts a fake docker on the PATH for the rest of the test and
+// returns the directory it keeps its files in
+func FakeDocker(t *testing.T) (dir string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker is a shell script")
+	}
+
+	dir = t.TempDir()
+	WriteDocker(t, dir, fakeDocker)
+
+	t.Setenv("FAKE_DOCKER", dir)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return dir
+}
+
+// WriteDocker replaces the fake docker in dir with another script
+func WriteDocker(t *testing.T, dir, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// SetDockerPs sets what `docker compose ps` of the fake docker in dir prints
+func SetDockerPs(t *testing.T, dir, ps string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "ps"), []byte(ps), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DockerCalls returns the arguments the fake docker in dir was called with,
+// one call per line
+func DockerCalls(t *testing.T, dir string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// ResetDocker lets a stopped `up` of the fake docker in dir run again
+func ResetDocker(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.Remove(filepath.Join(dir, "stopped")); err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
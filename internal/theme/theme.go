@@ -0,0 +1,363 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the colors everything ren draws is styled with
+type Theme struct {
+	Name string
+	// Plain is set when the theme has no colors at all, so that matches and
+	// selections are shown in reverse video instead
+	Plain bool
+
+	Border       lipgloss.TerminalColor
+	Accent       lipgloss.TerminalColor
+	Contrast     lipgloss.TerminalColor
+	Text         lipgloss.TerminalColor
+	Subtle       lipgloss.TerminalColor
+	Faint        lipgloss.TerminalColor
+	Command      lipgloss.TerminalColor
+	Description  lipgloss.TerminalColor
+	Divider      lipgloss.TerminalColor
+	Success      lipgloss.TerminalColor
+	Selected     lipgloss.TerminalColor
+	Info         lipgloss.TerminalColor
+	Warning      lipgloss.TerminalColor
+	Error        lipgloss.TerminalColor
+	Banner       lipgloss.TerminalColor
+	ReplayBanner lipgloss.TerminalColor
+	JSONKey      lipgloss.TerminalColor
+	Match        lipgloss.TerminalColor
+	CurrentMatch lipgloss.TerminalColor
+	MatchText    lipgloss.TerminalColor
+	Selection    lipgloss.TerminalColor
+	TableBorder  lipgloss.TerminalColor
+	TableHeader  lipgloss.TerminalColor
+	TableRow     lipgloss.TerminalColor
+	TableRowAlt  lipgloss.TerminalColor
+	// Processes are handed out to the processes in the logs tab
+	Processes []lipgloss.TerminalColor
+}
+
+// Definition is a theme in the config. it starts from a built-in theme and
+// overrides the colors it sets, as hex like "#ff00ff" or ANSI numbers like
+// "13", or "none" for the terminal's own color.
+type Definition struct {
+	Base         string   `json:"base"`
+	Border       string   `json:"border"`
+	Accent       string   `json:"accent"`
+	Contrast     string   `json:"contrast"`
+	Text         string   `json:"text"`
+	Subtle       string   `json:"subtle"`
+	Faint        string   `json:"faint"`
+	Command      string   `json:"command"`
+	Description  string   `json:"description"`
+	Divider      string   `json:"divider"`
+	Success      string   `json:"success"`
+	Selected     string   `json:"selected"`
+	Info         string   `json:"info"`
+	Warning      string   `json:"warning"`
+	Error        string   `json:"error"`
+	Banner       string   `json:"banner"`
+	ReplayBanner string   `json:"replay_banner"`
+	JSONKey      string   `json:"json_key"`
+	Match        string   `json:"match"`
+	CurrentMatch string   `json:"current_match"`
+	MatchText    string   `json:"match_text"`
+	Selection    string   `json:"selection"`
+	TableBorder  string   `json:"table_border"`
+	TableHeader  string   `json:"table_header"`
+	TableRow     string   `json:"table_row"`
+	TableRowAlt  string   `json:"table_row_alt"`
+	Processes    []string `json:"processes"`
+}
+
+// Default is the theme used when the config doesn't pick one
+const Default = "auto"
+
+func adaptive(light, dark string) lipgloss.AdaptiveColor {
+	return lipgloss.AdaptiveColor{Light: light, Dark: dark}
+}
+
+// auto picks the light or dark colors depending on the background of the
+// terminal
+var auto = Theme{
+	Name:         "auto",
+	Border:       adaptive("#656575", "#475569"),
+	Accent:       lipgloss.Color("#ff00ff"),
+	Contrast:     lipgloss.Color("#ffffff"),
+	Text:         adaptive("#606060", "#e0e0e0"),
+	Subtle:       adaptive("#a7a7a7", "#8a8a8a"),
+	Faint:        adaptive("#a7a7a7", "#6b6b6b"),
+	Command:      adaptive("#a7c7e7", "#8394a7"),
+	Description:  adaptive("#a7e7a7", "#8aa78a"),
+	Divider:      adaptive("#d3d3d3", "#5c5c5c"),
+	Success:      lipgloss.Color("#15803d"),
+	Selected:     lipgloss.Color("#555"),
+	Info:         lipgloss.Color("#2563eb"),
+	Warning:      adaptive("#b45309", "#fbbf24"),
+	Error:        adaptive("#b91c1c", "#f87171"),
+	Banner:       lipgloss.Color("#b91c1c"),
+	ReplayBanner: lipgloss.Color("#1d4ed8"),
+	JSONKey:      adaptive("#0e7490", "#67e8f9"),
+	Match:        lipgloss.Color("#fde047"),
+	CurrentMatch: lipgloss.Color("#fb923c"),
+	MatchText:    lipgloss.Color("#000000"),
+	Selection:    lipgloss.Color("#7e22ce"),
+	TableBorder:  lipgloss.Color("253"),
+	TableHeader:  lipgloss.Color("245"),
+	TableRow:     lipgloss.Color("245"),
+	TableRowAlt:  lipgloss.Color("241"),
+	Processes:    colors("#38bdf8", "#f472b6", "#a3e635", "#fbbf24", "#c084fc", "#2dd4bf", "#fb923c", "#f87171"),
+}
+
+var highContrast = Theme{
+	Name:         "high-contrast",
+	Border:       lipgloss.Color("15"),
+	Accent:       lipgloss.Color("13"),
+	Contrast:     lipgloss.Color("0"),
+	Text:         lipgloss.NoColor{},
+	Subtle:       lipgloss.NoColor{},
+	Faint:        lipgloss.NoColor{},
+	Command:      lipgloss.Color("14"),
+	Description:  lipgloss.Color("10"),
+	Divider:      lipgloss.Color("15"),
+	Success:      lipgloss.Color("10"),
+	Selected:     lipgloss.Color("13"),
+	Info:         lipgloss.Color("12"),
+	Warning:      lipgloss.Color("11"),
+	Error:        lipgloss.Color("9"),
+	Banner:       lipgloss.Color("9"),
+	ReplayBanner: lipgloss.Color("12"),
+	JSONKey:      lipgloss.Color("14"),
+	Match:        lipgloss.Color("11"),
+	CurrentMatch: lipgloss.Color("13"),
+	MatchText:    lipgloss.Color("0"),
+	Selection:    lipgloss.Color("14"),
+	TableBorder:  lipgloss.Color("15"),
+	TableHeader:  lipgloss.Color("15"),
+	TableRow:     lipgloss.NoColor{},
+	TableRowAlt:  lipgloss.NoColor{},
+	Processes:    colors("14", "13", "10", "11", "12", "9"),
+}
+
+func colors(values ...string) []lipgloss.TerminalColor {
+	c := make([]lipgloss.TerminalColor, len(values))
+	for i, v := range values {
+		c[i] = lipgloss.Color(v)
+	}
+	return c
+}
+
+// pick makes a theme out of either the light or the dark half of the
+// adaptive colors of auto
+func pick(name string, dark bool) Theme {
+	t := auto
+	t.Name = name
+	mapColors(&t, func(c lipgloss.TerminalColor) lipgloss.TerminalColor {
+		if a, ok := c.(lipgloss.AdaptiveColor); ok {
+			if dark {
+				return lipgloss.Color(a.Dark)
+			}
+			return lipgloss.Color(a.Light)
+		}
+		return c
+	})
+	return t
+}
+
+// plain has no colors, for NO_COLOR and terminals without them
+func plain() Theme {
+	t := auto
+	t.Name = "none"
+	t.Plain = true
+	mapColors(&t, func(lipgloss.TerminalColor) lipgloss.TerminalColor {
+		return lipgloss.NoColor{}
+	})
+	return t
+}
+
+// mapColors replaces every color of a theme
+func mapColors(t *Theme, f func(lipgloss.TerminalColor) lipgloss.TerminalColor) {
+	for _, c := range t.roles() {
+		*c.color = f(*c.color)
+	}
+
+	processes := make([]lipgloss.TerminalColor, len(t.Processes))
+	for i, c := range t.Processes {
+		processes[i] = f(c)
+	}
+	t.Processes = processes
+}
+
+// role is one of the colors of a theme, by the name used in the config
+type role struct {
+	name  string
+	color *lipgloss.TerminalColor
+}
+
+func (t *Theme) roles() []role {
+	return []role{
+		{"border", &t.Border},
+		{"accent", &t.Accent},
+		{"contrast", &t.Contrast},
+		{"text", &t.Text},
+		{"subtle", &t.Subtle},
+		{"faint", &t.Faint},
+		{"command", &t.Command},
+		{"description", &t.Description},
+		{"divider", &t.Divider},
+		{"success", &t.Success},
+		{"selected", &t.Selected},
+		{"info", &t.Info},
+		{"warning", &t.Warning},
+		{"error", &t.Error},
+		{"banner", &t.Banner},
+		{"replay_banner", &t.ReplayBanner},
+		{"json_key", &t.JSONKey},
+		{"match", &t.Match},
+		{"current_match", &t.CurrentMatch},
+		{"match_text", &t.MatchText},
+		{"selection", &t.Selection},
+		{"table_border", &t.TableBorder},
+		{"table_header", &t.TableHeader},
+		{"table_row", &t.TableRow},
+		{"table_row_alt", &t.TableRowAlt},
+	}
+}
+
+// Builtin returns one of the themes that come with ren
+func Builtin(name string) (Theme, bool) {
+	switch name {
+	case "auto":
+		return auto, true
+	case "dark":
+		return pick("dark", true), true
+	case "light":
+		return pick("light", false), true
+	case "high-contrast":
+		return highContrast, true
+	case "none":
+		return plain(), true
+	}
+	return Theme{}, false
+}
+
+// Builtins are the names of the themes that come with ren
+var Builtins = []string{"auto", "dark", "light", "high-contrast", "none"}
+
+// Resolve looks up a theme by name, either a built-in one or one defined in
+// the config
+func Resolve(name string, custom map[string]Definition) (Theme, error) {
+	if name == "" {
+		name = Default
+	}
+
+	if t, ok := Builtin(name); ok {
+		return t, nil
+	}
+
+	def, ok := custom[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q, use one of %s", name, strings.Join(names(custom), ", "))
+	}
+
+	base := def.Base
+	if base == "" {
+		base = Default
+	}
+	t, ok := Builtin(base)
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %q: unknown base %q, use one of %s", name, base, strings.Join(Builtins, ", "))
+	}
+	t.Name = name
+
+	if err := def.apply(&t); err != nil {
+		return Theme{}, fmt.Errorf("theme %q: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// Load resolves the theme to use. NO_COLOR in the environment wins over the
+// config, see https://no-color.org.
+func Load(name string, custom map[string]Definition) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return plain(), nil
+	}
+	return Resolve(name, custom)
+}
+
+// values lists the colors the definition sets, in the order of roles
+func (d Definition) values() []string {
+	return []string{
+		d.Border, d.Accent, d.Contrast, d.Text, d.Subtle, d.Faint, d.Command,
+		d.Description, d.Divider, d.Success, d.Selected, d.Info, d.Warning,
+		d.Error, d.Banner, d.ReplayBanner, d.JSONKey, d.Match, d.CurrentMatch,
+		d.MatchText, d.Selection, d.TableBorder, d.TableHeader, d.TableRow,
+		d.TableRowAlt,
+	}
+}
+
+// apply overrides the colors of a theme with the ones the definition sets
+func (d Definition) apply(t *Theme) error {
+	values := d.values()
+	for i, r := range t.roles() {
+		if values[i] == "" {
+			continue
+		}
+
+		c, err := parseColor(values[i])
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.name, err)
+		}
+		*r.color = c
+	}
+
+	if len(d.Processes) > 0 {
+		t.Processes = make([]lipgloss.TerminalColor, len(d.Processes))
+		for i, value := range d.Processes {
+			c, err := parseColor(value)
+			if err != nil {
+				return fmt.Errorf("processes: %w", err)
+			}
+			t.Processes[i] = c
+		}
+	}
+
+	return nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseColor reads a color from the config
+func parseColor(value string) (lipgloss.TerminalColor, error) {
+	if value == "none" {
+		return lipgloss.NoColor{}, nil
+	}
+	if hexColor.MatchString(value) {
+		return lipgloss.Color(value), nil
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(value), nil
+	}
+	return nil, fmt.Errorf("invalid color %q, use hex like \"#ff00ff\", an ANSI number from 0 to 255 or \"none\"", value)
+}
+
+// names lists the built-in themes followed by the ones from the config
+func names(custom map[string]Definition) []string {
+	var defined []string
+	for name := range custom {
+		defined = append(defined, name)
+	}
+	sort.Strings(defined)
+	return append(append([]string{}, Builtins...), defined...)
+}
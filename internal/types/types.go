@@ -3,8 +3,9 @@ package types
 import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thejawker/rennen/internal/keys"
+	"github.com/thejawker/rennen/internal/loglevel"
 	"github.com/thejawker/rennen/internal/process"
-	"sync"
 	"time"
 )
 
@@ -15,15 +16,153 @@ type Model struct {
 	ActiveTab       int
 	WindowSize      tea.WindowSizeMsg
 	Viewport        *viewport.Model
-	Mutex           sync.Mutex
 	StartedAt       time.Time
 	SelectedCommand int
+	ConfigError     string
+	InsertMode      InsertMode
+	InputLine       string
+	Muted           map[string]bool
+	LegendCursor    int
+	Timestamps      TimestampMode
+	Search          string
+	Searching       bool
+	SearchSeq       uint64
+	SearchStatus    string
+	Prompting       bool
+	Selecting       bool
+	SelectionFrom   uint64
+	SelectionTo     uint64
+	CopyStatus      string
+	Keys            keys.KeyMap
+	ShowHelp        bool
+	PaletteOpen     bool
+	PaletteEntries  []string
+	PaletteSelected int
+	Layout          Layout
+	Replay          string
+	ReadOnly        bool
+	CPUWarning      float64
+	MemoryWarning   int64
 }
 
+// TimestampMode tells how timestamps are shown in front of output lines
+type TimestampMode int
+
+const (
+	TimestampsOff TimestampMode = iota
+	// TimestampsRelative shows how long ago a line came in
+	TimestampsRelative
+	// TimestampsAbsolute shows the time of day a line came in
+	TimestampsAbsolute
+)
+
+// Layout tells how the tabs and the window are arranged
+type Layout int
+
+const (
+	LayoutDefault Layout = iota
+	// LayoutCompact drops the borders so the output gets the whole terminal
+	LayoutCompact
+	// LayoutSidebar lists the tabs down the left side instead of on top
+	LayoutSidebar
+)
+
+// InsertMode tells whether key presses go to ren or to the active process
+type InsertMode int
+
+const (
+	InsertNone InsertMode = iota
+	// InsertKeys forwards every key press as it happens
+	InsertKeys
+	// InsertLine lets the user edit a line that's sent on enter
+	InsertLine
+)
+
 type Tab struct {
 	Name         string
 	Notification bool
+	// Alert is the severity of the trigger that marked the tab, if any
+	Alert string
+	// Unread and UnreadErrors count the lines and errors that came in since
+	// the tab was last looked at, which is when it had SeenLines and
+	// SeenErrors
+	Unread       int
+	UnreadErrors int
+	SeenLines    uint64
+	SeenErrors   uint64
 	Status       string
+	// MinLevel hides the output below this log level
+	MinLevel loglevel.Level
+	// ExpandedSeq is the JSON line shown as the whole indented object, by
+	// sequence number, or 0 for none
+	ExpandedSeq uint64
+	Kind        TabKind
+	Scroll      Scroll
+	Panes       []Pane
+	Focus       int
+	Columns     int
+}
+
+// TabKind tells what a tab shows
+type TabKind int
+
+const (
+	TabOverview TabKind = iota
+	TabProcess
+	// TabLogs interleaves the output of all processes
+	TabLogs
+	// TabView tiles the output of several processes in a grid of panes
+	TabView
+)
+
+// ProcessNumber is the number of the process a tab shows, counting the
+// process tabs from 1, or 0 for other tabs
+func ProcessNumber(tabs []Tab, i int) int {
+	if i < 0 || i >= len(tabs) || tabs[i].Kind != TabProcess {
+		return 0
+	}
+
+	n := 0
+	for _, t := range tabs[:i+1] {
+		if t.Kind == TabProcess {
+			n++
+		}
+	}
+	return n
+}
+
+// ProcessTab returns the index of the tab of the process with number n, or
+// -1 when there is no such process
+func ProcessTab(tabs []Tab, n int) int {
+	for i, t := range tabs {
+		if t.Kind != TabProcess {
+			continue
+		}
+		if n--; n == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Pane is a single process shown in a view tab
+type Pane struct {
+	Process string
+	Scroll  Scroll
+}
+
+// Scroll is the position in a scrollable output. the zero value follows
+// the output as it comes in.
+type Scroll struct {
+	// Offset is the number of lines scrolled up from the bottom
+	Offset int
+	// Lines is the number of lines there were when Offset was last updated
+	Lines int
+}
+
+// Following reports whether new output is scrolled into view
+func (s Scroll) Following() bool {
+	return s.Offset == 0
 }
 
 type ViewModelProvider interface {
@@ -33,5 +172,6 @@ type ViewModelProvider interface {
 	IsOverview() bool
 	GetRunTime() string
 	GetCommandByName(name string) *process.Process
+	GetProcessByName(name string) *process.Process
 	GetActiveCommands() []*process.Process
 }
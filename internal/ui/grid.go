@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+var (
+	paneStyle        lipgloss.Style
+	focusedPaneStyle lipgloss.Style
+	paneTitleStyle   = lipgloss.NewStyle().Bold(true)
+	paneStatusStyle  lipgloss.Style
+)
+
+// renderGrid tiles the processes of a view tab in rows and columns
+func renderGrid(m types.ViewModelProvider, tab types.Tab, width, height int) string {
+	k := m.GetViewModel().Keys
+	hintText := hints(
+		item("tabs", k.PrevTab, k.NextTab),
+		item("focus", k.FocusNext),
+		item("scroll", k.Up, k.Down),
+		item("clear", k.Clear),
+		item("close", k.Close),
+		item("restart", k.Restart),
+		item("ungrid", k.Grid),
+		item("timestamps", k.Timestamps),
+		item("search", k.Search),
+		item("help", k.Help),
+	)
+	hint := renderHint(m.GetViewModel(), width, hintText)
+	height -= lipgloss.Height(hint)
+
+	if len(tab.Panes) == 0 {
+		return hint
+	}
+
+	columns := gridColumns(len(tab.Panes), tab.Columns)
+	rows := int(math.Ceil(float64(len(tab.Panes)) / float64(columns)))
+
+	var renderedRows []string
+	for row := 0; row < rows; row++ {
+		// the last row gets whatever height is left over from rounding
+		paneHeight := height / rows
+		if row == rows-1 {
+			paneHeight = height - paneHeight*(rows-1)
+		}
+
+		var panes []string
+		for col := 0; col < columns; col++ {
+			index := row*columns + col
+			if index >= len(tab.Panes) {
+				break
+			}
+
+			paneWidth := width / columns
+			if col == columns-1 {
+				paneWidth = width - paneWidth*(columns-1)
+			}
+
+			panes = append(panes, renderPane(m, tab.Panes[index], index == tab.Focus, paneWidth, paneHeight))
+		}
+
+		renderedRows = append(renderedRows, lipgloss.JoinHorizontal(lipgloss.Top, panes...))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, renderedRows...) + "\n" + hint
+}
+
+// gridColumns picks the number of columns, keeping the grid roughly square
+// unless the view configures it
+func gridColumns(panes, configured int) int {
+	if configured > 0 {
+		return min(configured, panes)
+	}
+	if panes <= 3 {
+		return panes
+	}
+	return int(math.Ceil(math.Sqrt(float64(panes))))
+}
+
+func renderPane(m types.ViewModelProvider, pane types.Pane, focused bool, width, height int) string {
+	style := paneStyle
+	if focused {
+		style = focusedPaneStyle
+	}
+
+	innerWidth := max(0, width-style.GetHorizontalFrameSize())
+	innerHeight := max(0, height-style.GetVerticalFrameSize())
+
+	proc := m.GetProcessByName(pane.Process)
+	if proc == nil {
+		return style.Width(width - style.GetHorizontalBorderSize()).Height(innerHeight).Render(pane.Process + " is gone")
+	}
+
+	status := ""
+	if startedAt := proc.StartTime(); startedAt != nil {
+		status = utils.RelativeTime(*startedAt)
+	}
+	if proc.IsStopped() {
+		status = "stopped"
+	}
+	if !pane.Scroll.Following() {
+		status = fmt.Sprintf("%s ↓%d", status, pane.Scroll.Offset)
+	}
+
+	title := paneTitleStyle.Render(proc.Shortname) + " " + paneStatusStyle.Render(status)
+	title = lipgloss.NewStyle().MaxWidth(innerWidth).Render(title)
+
+	vp := viewport.New(innerWidth, max(0, innerHeight-1))
+	vp.SetContent(renderLines(proc.Tail(vp.Height+pane.Scroll.Offset), m.GetViewModel()))
+	vp.GotoBottom()
+	vp.LineUp(pane.Scroll.Offset)
+
+	return style.
+		Width(width - style.GetHorizontalBorderSize()).
+		Height(innerHeight).
+		Render(title + "\n" + vp.View())
+}
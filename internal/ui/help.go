@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/keys"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+var (
+	helpTitleStyle lipgloss.Style
+	helpKeyStyle   = lipgloss.NewStyle().Bold(true)
+	helpDescStyle  lipgloss.Style
+)
+
+// hintItem is a part of a hint line, one or more bindings with a short label
+type hintItem struct {
+	label    string
+	bindings []key.Binding
+}
+
+func item(label string, bindings ...key.Binding) hintItem {
+	return hintItem{label: label, bindings: bindings}
+}
+
+// hints renders a hint line from key bindings, leaving out the disabled ones
+func hints(items ...hintItem) string {
+	parts := make([]string, 0, len(items))
+	for _, it := range items {
+		var names []string
+		for _, b := range it.bindings {
+			if b.Enabled() {
+				names = append(names, b.Help().Key)
+			}
+		}
+		if len(names) > 0 {
+			parts = append(parts, strings.Join(names, "/")+" "+it.label)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keyName is the first key of a binding, for hints inside sentences
+func keyName(b key.Binding) string {
+	return b.Help().Key
+}
+
+// helpGroups are the columns of the help overlay
+func helpGroups(k keys.KeyMap) [][]key.Binding {
+	return [][]key.Binding{
+		{k.NextTab, k.PrevTab, k.FocusNext, k.FocusPrev, k.Jump, k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom, k.Help, k.Palette, k.Quit},
+		{k.Trigger, k.Restart, k.Close, k.Clear, k.Insert, k.InsertLine, k.Grid, k.PickPrev, k.PickNext, k.Mute},
+		{k.Timestamps, k.Warnings, k.Expand, k.Search, k.NextMatch, k.PrevMatch, k.Cancel, k.Select, k.Copy, k.CopyLast, k.CopyAll},
+	}
+}
+
+// renderHelp renders every key binding, in columns when they fit
+func renderHelp(vm types.Model, width int) string {
+	var columns []string
+	for _, group := range helpGroups(vm.Keys) {
+		var rows []string
+		for _, b := range group {
+			if !b.Enabled() {
+				continue
+			}
+			names := make([]string, len(b.Keys()))
+			for i, k := range b.Keys() {
+				names[i] = keys.Display(k)
+			}
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top,
+				helpKeyStyle.Width(12).Render(strings.Join(names, " ")),
+				helpDescStyle.Render(b.Help().Desc)))
+		}
+		columns = append(columns, lipgloss.NewStyle().PaddingRight(4).Render(strings.Join(rows, "\n")))
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	if lipgloss.Width(body) > width {
+		body = lipgloss.JoinVertical(lipgloss.Left, columns...)
+	}
+
+	return helpTitleStyle.Render("keys") + "\n\n" + body + "\n\n" + hintStyle.Render("press any key to close")
+}
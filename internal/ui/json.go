@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+var (
+	jsonKeyStyle   lipgloss.Style
+	jsonValueStyle lipgloss.Style
+
+	// the fields that are pulled to the front, in the order they're tried
+	jsonTimeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t"}
+	jsonLevelKeys   = []string{"level", "lvl", "severity", "L"}
+	jsonMessageKeys = []string{"msg", "message", "M"}
+)
+
+// jsonField is a field of a JSON line, kept in the order it was written
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// renderJSON renders a line that holds a JSON object as time, level and
+// message followed by the other fields as key=value, or expanded into the
+// indented object. whatever comes before the object stays in front of it.
+func renderJSON(line process.Line, expanded bool) (string, bool) {
+	start := utils.FindJSONObject(line.Text, true)
+	if start < 0 {
+		return "", false
+	}
+
+	prefix, object := line.Text[:start], strings.TrimSpace(line.Text[start:])
+	if prefix != "" {
+		prefix = outputStyle.Render(prefix)
+	}
+
+	if expanded {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(object), "", "  "); err != nil {
+			return "", false
+		}
+		return prefix + outputStyle.Render(indented.String()), true
+	}
+
+	fields, err := parseJSONFields(object)
+	if err != nil {
+		return "", false
+	}
+
+	var parts []string
+	if t, ok := takeField(&fields, jsonTimeKeys); ok {
+		parts = append(parts, timestampStyle.Render(formatJSONTime(t)))
+	}
+	if level, ok := takeField(&fields, jsonLevelKeys); ok {
+		parts = append(parts, lineStyle(line).Bold(true).Render(strings.ToUpper(jsonString(level))))
+	}
+	if msg, ok := takeField(&fields, jsonMessageKeys); ok {
+		parts = append(parts, lineStyle(line).Render(jsonString(msg)))
+	}
+	for _, field := range fields {
+		parts = append(parts, jsonKeyStyle.Render(field.key+"=")+jsonValueStyle.Render(jsonValue(field.value)))
+	}
+
+	return prefix + strings.Join(parts, " "), true
+}
+
+// parseJSONFields reads the top level fields of an object in order
+func parseJSONFields(object string) ([]jsonField, error) {
+	decoder := json.NewDecoder(strings.NewReader(object))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	var fields []jsonField
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, jsonField{key: fmt.Sprint(token), value: value})
+	}
+
+	return fields, nil
+}
+
+// takeField removes the first field with one of the keys and returns its value
+func takeField(fields *[]jsonField, keys []string) (json.RawMessage, bool) {
+	for _, key := range keys {
+		for i, field := range *fields {
+			if field.key == key {
+				*fields = append((*fields)[:i], (*fields)[i+1:]...)
+				return field.value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// jsonString returns a string value without its quotes, and anything else
+// as it was written
+func jsonString(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s
+	}
+	return string(value)
+}
+
+// jsonValue formats a value for key=value, quoting strings only when they
+// would be hard to read otherwise
+func jsonValue(value json.RawMessage) string {
+	s := jsonString(value)
+	if value[0] == '"' && (s == "" || strings.ContainsAny(s, " =\"")) {
+		return string(value)
+	}
+	return s
+}
+
+// formatJSONTime shortens a timestamp to the time of day, which is either a
+// string like RFC 3339 or a unix time in seconds or milliseconds
+func formatJSONTime(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.Local().Format("15:04:05.000")
+			}
+		}
+		return s
+	}
+
+	var n float64
+	if json.Unmarshal(value, &n) == nil {
+		if n > 1e12 {
+			n /= 1000
+		}
+		seconds, fraction := math.Modf(n)
+		return time.Unix(int64(seconds), int64(math.Round(fraction*1e3))*1e6).Format("15:04:05.000")
+	}
+
+	return string(value)
+}
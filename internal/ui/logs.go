@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+var mutedStyle = lipgloss.NewStyle().Faint(true).Strikethrough(true)
+
+// shortnameColor picks a color for the process based on its name, so it
+// stays the same when processes are added or removed
+func shortnameColor(name string) lipgloss.TerminalColor {
+	if len(colors.Processes) == 0 {
+		return lipgloss.NoColor{}
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return colors.Processes[h.Sum32()%uint32(len(colors.Processes))]
+}
+
+// taggedLine is a line together with the process it came from
+type taggedLine struct {
+	process.Line
+	shortname string
+}
+
+// renderLogs interleaves the output of all processes that aren't muted, in
+// the order the lines came in
+func renderLogs(m types.ViewModelProvider, tab types.Tab, width, height int) string {
+	vm := m.GetViewModel()
+
+	legend := renderLegend(vm, width)
+	k := vm.Keys
+	hint := renderHint(vm, width, hints(
+		item("tabs", k.PrevTab, k.NextTab),
+		item("scroll", k.Up, k.Down),
+		item("pick", k.PickPrev, k.PickNext),
+		item("mute", k.Mute),
+		item("timestamps", k.Timestamps),
+		item("search", k.Search),
+		item("help", k.Help),
+	))
+	height -= lipgloss.Height(legend) + lipgloss.Height(hint) + 1
+
+	nameWidth := 0
+	for _, p := range vm.Processes {
+		nameWidth = max(nameWidth, lipgloss.Width(p.Shortname))
+	}
+
+	wanted := max(0, height) + tab.Scroll.Offset
+
+	var lines []taggedLine
+	for _, p := range vm.Processes {
+		if vm.Muted[p.Shortname] {
+			continue
+		}
+		for _, line := range p.Tail(wanted) {
+			lines = append(lines, taggedLine{Line: line, shortname: p.Shortname})
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Seq < lines[j].Seq
+	})
+
+	end := max(0, len(lines)-tab.Scroll.Offset)
+	start := max(0, end-max(0, height))
+	lines = lines[start:end]
+
+	rendered := make([]string, 0, height)
+	for _, line := range lines {
+		prefix := lipgloss.NewStyle().
+			Foreground(shortnameColor(line.shortname)).
+			Width(nameWidth).
+			Render(line.shortname)
+
+		prefix = renderTimestamp(line.Line, vm.Timestamps) + prefix
+
+		text := lipgloss.NewStyle().MaxWidth(width).Render(prefix + " " + renderGutter(line.Line) + renderLineText(line.Line, vm))
+		rendered = append(rendered, text)
+	}
+
+	// keep the hint at the bottom when there's little output
+	for len(rendered) < height {
+		rendered = append([]string{""}, rendered...)
+	}
+
+	return legend + "\n\n" + strings.Join(rendered, "\n") + "\n" + hint
+}
+
+// renderLegend lists the processes with their colors, showing which ones are muted
+func renderLegend(vm types.Model, width int) string {
+	items := make([]string, 0, len(vm.Processes))
+	for i, p := range vm.Processes {
+		marker := "●"
+		style := lipgloss.NewStyle().Foreground(shortnameColor(p.Shortname))
+		if vm.Muted[p.Shortname] {
+			marker = "○"
+			style = mutedStyle
+		}
+
+		item := style.Render(marker + " " + p.Shortname)
+		if i == vm.LegendCursor {
+			item = lipgloss.NewStyle().Underline(true).Render("›") + item
+		} else {
+			item = " " + item
+		}
+
+		items = append(items, item)
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(items, "  "))
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+// TargetKind tells what kind of thing was clicked
+type TargetKind int
+
+const (
+	TargetNone TargetKind = iota
+	// TargetTab is a tab, by its index in the tabs
+	TargetTab
+	// TargetCommand is a command in the list of the overview, by its index
+	// in the commands
+	TargetCommand
+	// TargetProcess is a row of the table in the overview, by the index of
+	// its process in the processes
+	TargetProcess
+)
+
+// Target is what's under the mouse
+type Target struct {
+	Kind  TargetKind
+	Index int
+}
+
+// HitTest works out what's drawn at a cell of the screen, using the same
+// geometry the view is rendered with
+func HitTest(m types.ViewModelProvider, x, y int) Target {
+	vm := m.GetViewModel()
+	if len(vm.Tabs) == 0 {
+		return Target{}
+	}
+
+	bannerHeight := 0
+	if banner := renderBanner(vm); banner != "" {
+		bannerHeight = lipgloss.Height(banner)
+	}
+	y -= bannerHeight
+	if y < 0 {
+		return Target{}
+	}
+
+	if vm.Layout == types.LayoutSidebar {
+		return sidebarAt(m, x, y, vm.WindowSize.Height-bannerHeight)
+	}
+
+	tabsHeight := activeTabStyle.GetVerticalFrameSize() + 1
+	if vm.Layout == types.LayoutCompact {
+		tabsHeight = 1
+	}
+	if y < tabsHeight {
+		return tabAt(vm, x)
+	}
+	y -= tabsHeight
+
+	if !m.IsOverview() || vm.ShowHelp || vm.PaletteOpen {
+		return Target{}
+	}
+
+	maxLines := vm.WindowSize.Height - bannerHeight - tabsHeight - frameStyle(vm).GetVerticalFrameSize()
+	return overviewAt(m, y, maxLines)
+}
+
+// tabAt finds the tab at a column of the tab bar
+func tabAt(vm types.Model, x int) Target {
+	var widths []int
+	var first int
+	if vm.Layout == types.LayoutCompact {
+		tabs, from, last, overflow := compactTabs(vm)
+		if overflow {
+			tabs, first = tabs[from:last+1], from
+			x -= lipgloss.Width(compactIndicator(true, "‹"))
+		}
+		for _, tab := range tabs {
+			widths = append(widths, lipgloss.Width(tab))
+		}
+	} else {
+		strip := layoutTabs(vm)
+		if strip.overflow {
+			first = strip.first
+			x -= lipgloss.Width(overflowIndicator(true, "‹", "├"))
+		}
+		for _, width := range strip.widths {
+			widths = append(widths, width+activeTabStyle.GetHorizontalBorderSize())
+		}
+	}
+	if x < 0 {
+		return Target{}
+	}
+
+	for i, width := range widths {
+		if x < width {
+			return Target{Kind: TargetTab, Index: first + i}
+		}
+		x -= width
+	}
+
+	return Target{}
+}
+
+// sidebarAt finds the tab in the sidebar, or what's in the window next to
+// it, for the sidebar layout
+func sidebarAt(m types.ViewModelProvider, x, y, height int) Target {
+	vm := m.GetViewModel()
+	if x < sidebarWidth(vm) {
+		// a line that says there are more tabs goes to the one it hides
+		rows := max(1, height-sidebarStyle.GetVerticalFrameSize())
+		first, last := sidebarRange(vm, rows)
+		row := y - sidebarStyle.GetBorderTopSize()
+		if row < 0 || row > last-first {
+			return Target{}
+		}
+		return Target{Kind: TargetTab, Index: first + row}
+	}
+
+	if !m.IsOverview() || vm.ShowHelp || vm.PaletteOpen {
+		return Target{}
+	}
+
+	frame := frameStyle(vm)
+	return overviewAt(m, y-frame.GetBorderTopSize(), height-frame.GetVerticalFrameSize())
+}
+
+// overviewAt finds the command or process at a line of the overview, which
+// starts with the list of commands followed by a blank line and the table
+func overviewAt(m types.ViewModelProvider, y, maxLines int) Target {
+	vm := m.GetViewModel()
+	width := contentWidth(vm)
+
+	// the list starts with a title line
+	list := renderCommandList(m, width)
+	if len(vm.Commands) > 0 && y >= 1 && y <= len(vm.Commands) {
+		return Target{Kind: TargetCommand, Index: y - 1}
+	}
+
+	// the table has a border, the headers and a line below them on top
+	top := lipgloss.Height(list) + 1
+	tableHeight := maxLines - len(strings.Split(list, "\n")) - 2
+	row := top + 3
+	commands := len(m.GetActiveCommands())
+	for i, height := range processTable(m, width).RowHeights() {
+		if row >= top+tableHeight {
+			break
+		}
+		if y >= row && y < row+height {
+			if i < commands {
+				return Target{}
+			}
+			return Target{Kind: TargetProcess, Index: i - commands}
+		}
+		row += height
+	}
+
+	return Target{}
+}
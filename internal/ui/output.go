@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/loglevel"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+var (
+	outputStyle    lipgloss.Style
+	markerStyle    lipgloss.Style
+	timestampStyle lipgloss.Style
+	stdoutGutter   = " "
+	stderrGutter   string
+
+	// levelStyles color lines by their log level, the others use outputStyle
+	levelStyles map[loglevel.Level]lipgloss.Style
+
+	matchStyle        lipgloss.Style
+	currentMatchStyle lipgloss.Style
+	selectedStyle     lipgloss.Style
+)
+
+// renderLines renders output lines with a gutter that marks stderr and,
+// depending on the mode, a timestamp in front of them
+func renderLines(lines []process.Line, vm types.Model) string {
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		rendered[i] = renderTimestamp(line, vm.Timestamps) + renderGutter(line) + renderLineText(line, vm)
+	}
+	return strings.Join(rendered, "\n")
+}
+
+func renderGutter(line process.Line) string {
+	if line.Stream == process.StreamStderr {
+		return stderrGutter
+	}
+	return stdoutGutter
+}
+
+func renderLineText(line process.Line, vm types.Model) string {
+	if vm.Selecting && line.Seq >= vm.SelectionFrom && line.Seq <= vm.SelectionTo {
+		return selectedStyle.Render(utils.StripAnsi(line.Text))
+	}
+	if highlighted, ok := highlightMatches(line, vm.Search, vm.SearchSeq); ok {
+		return highlighted
+	}
+	if line.Stream == process.StreamRen {
+		return markerStyle.Render(line.Text)
+	}
+	if line.JSON {
+		if rendered, ok := renderJSON(line, line.Seq == vm.Tabs[vm.ActiveTab].ExpandedSeq); ok {
+			return rendered
+		}
+	}
+	return lineStyle(line).Render(line.Text)
+}
+
+// lineStyle is the style of a line of output, which depends on its level
+func lineStyle(line process.Line) lipgloss.Style {
+	if style, ok := levelStyles[line.Level]; ok {
+		return style
+	}
+	return outputStyle
+}
+
+func renderTimestamp(line process.Line, mode types.TimestampMode) string {
+	switch mode {
+	case types.TimestampsAbsolute:
+		return timestampStyle.Render(line.Time.Format("15:04:05.000")) + " "
+	case types.TimestampsRelative:
+		return timestampStyle.Render(fmt.Sprintf("%8s", relativeAge(line.Time))) + " "
+	}
+	return ""
+}
+
+// relativeAge formats how long ago something happened in a few characters
+func relativeAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds ago", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+}
+
+// highlightMatches marks where the search query occurs in the line. the
+// colors of the line itself are dropped, since they can't be mixed.
+func highlightMatches(line process.Line, query string, current uint64) (string, bool) {
+	if query == "" {
+		return "", false
+	}
+
+	text := utils.StripAnsi(line.Text)
+	haystack, needle := strings.ToLower(text), strings.ToLower(query)
+
+	// lowercasing can change the length of some characters
+	if len(haystack) != len(text) {
+		haystack, needle = text, query
+	}
+
+	if !strings.Contains(haystack, needle) {
+		return "", false
+	}
+
+	style, rest := matchStyle, lineStyle(line)
+	if line.Seq == current {
+		style = currentMatchStyle
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(haystack, needle)
+		if i < 0 {
+			b.WriteString(rest.Render(text))
+			return b.String(), true
+		}
+
+		b.WriteString(rest.Render(text[:i]))
+		b.WriteString(style.Render(text[i : i+len(needle)]))
+		text, haystack = text[i+len(needle):], haystack[i+len(needle):]
+	}
+}
@@ -3,19 +3,32 @@ package ui
 import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/process"
+	"github.com/thejawker/rennen/internal/procstat"
 	"github.com/thejawker/rennen/internal/types"
 	"github.com/thejawker/rennen/internal/utils"
+	"slices"
 	"strings"
 	"time"
 )
 
 func renderOverview(m types.ViewModelProvider, maxLines int) string {
-	windowWidth := m.GetViewModel().WindowSize.Width - windowStyle.GetHorizontalFrameSize() - 2
+	windowWidth := contentWidth(m.GetViewModel())
 	commandList := renderCommandList(m, windowWidth)
 	commandLines := strings.Split(commandList, "\n")
 	processTable := renderProcessTable(m, maxLines-len(commandLines)-2, windowWidth)
 
-	hint := hintStyle.Width(windowWidth).Render("←/→ tabs, ↑/↓ select, ↵ trigger command, (q)uit all")
+	k := m.GetViewModel().Keys
+	hintText := hints(
+		item("tabs", k.PrevTab, k.NextTab),
+		item("jump", k.Jump),
+		item("select", k.Up, k.Down),
+		item("trigger command", k.Trigger),
+		item("palette", k.Palette),
+		item("help", k.Help),
+		item("quit all", k.Quit),
+	)
+	hint := hintStyle.Width(windowWidth).Render(hintText)
 
 	return fmt.Sprintf("%s\n\n%s\n%s", commandList, processTable, hint)
 }
@@ -30,7 +43,7 @@ func renderCommandList(m types.ViewModelProvider, width int) string {
 		BorderTop(false).
 		BorderBottom(false).
 		PaddingLeft(1).
-		BorderForeground(lipgloss.Color("253"))
+		BorderForeground(colors.TableBorder)
 
 	if len(m.GetViewModel().Commands) == 0 {
 		list += lipgloss.NewStyle().
@@ -46,7 +59,7 @@ func renderCommandList(m types.ViewModelProvider, width int) string {
 
 		prefix := " "
 		if i == m.GetViewModel().SelectedCommand {
-			style = style.Foreground(lipgloss.Color("#555"))
+			style = style.Foreground(colors.Selected)
 			prefix = "›"
 		}
 
@@ -54,13 +67,13 @@ func renderCommandList(m types.ViewModelProvider, width int) string {
 		prefix = lipgloss.NewStyle().
 			PaddingRight(1).
 			PaddingRight(1).
-			Foreground(lipgloss.Color("#ff00ff")).
+			Foreground(colors.Accent).
 			Render(prefix)
 
 		status := "•"
 		// we check whether the command is running or not and if it has been active within last 10 seconds
 		if cmd.LastActivity.Add(3 * time.Second).After(time.Now()) {
-			style = style.Foreground(lipgloss.Color("#15803d"))
+			style = style.Foreground(colors.Success)
 			status = "✓"
 		}
 
@@ -73,21 +86,79 @@ func renderCommandList(m types.ViewModelProvider, width int) string {
 }
 
 func renderProcessTable(m types.ViewModelProvider, height, width int) string {
-	table := NewTable().
-		SetColumns([]string{"command / process", "output", "status"}).
-		SetColumnWidth("command / process", 20).
-		SetColumnWidth("status", 10).
-		SetTotalWidth(width - 2)
+	return lipgloss.NewStyle().
+		MaxHeight(height).
+		Height(height).
+		Render(processTable(m, width).Render())
+}
+
+// optionalColumns are left out of the process table, in this order, when the
+// output column would get narrower than minOutput. the usage only goes
+// when the table wouldn't fit otherwise.
+var optionalColumns = []struct {
+	name      string
+	minOutput int
+}{
+	{"trend", 40},
+	{"matches", 40},
+	{"mem", 16},
+	{"cpu", 16},
+}
+
+// processTable lists the active commands followed by the processes
+func processTable(m types.ViewModelProvider, width int) *Table {
+	columns := []string{"command / process", "output", "matches", "status"}
+	if procstat.Supported {
+		columns = []string{"command / process", "output", "matches", "cpu", "mem", "trend", "status"}
+	}
+
+	widths := map[string]int{
+		"command / process": 20,
+		"matches":           7,
+		"cpu":               6,
+		"mem":               7,
+		"trend":             usageTrendWidth,
+		"status":            10,
+	}
+
+	// the width left for the output, after the other columns and the borders
+	// between them
+	outputWidth := func() int {
+		remaining := width - len(columns) + 1
+		for _, column := range columns {
+			remaining -= widths[column]
+		}
+		return remaining
+	}
+
+	for _, optional := range optionalColumns {
+		if outputWidth() >= optional.minOutput {
+			continue
+		}
+		columns = slices.DeleteFunc(columns, func(column string) bool {
+			return column == optional.name
+		})
+	}
 
+	table := NewTable().SetColumns(columns)
+	for _, column := range columns {
+		if w, ok := widths[column]; ok {
+			table.SetColumnWidth(column, w)
+		}
+	}
+	// the output is cut short rather than pushing the other columns out
+	table.SetColumnWidth("output", max(1, outputWidth()))
+
+	vm := m.GetViewModel()
 	commands := m.GetActiveCommands()
-	processes := m.GetViewModel().Processes
+	processes := vm.Processes
 	combined := append(commands, processes...)
 
 	for idx, proc := range combined {
 		status := ""
 
-		if proc.StartedAt != nil {
-			status = utils.RelativeTime(*proc.StartedAt)
+		if startedAt := proc.StartTime(); startedAt != nil {
+			status = utils.RelativeTime(*startedAt)
 		}
 
 		if proc.IsStopped() {
@@ -98,11 +169,110 @@ func renderProcessTable(m types.ViewModelProvider, height, width int) string {
 			status = "triggered"
 		}
 
-		table.AddRow([]string{proc.Shortname, proc.GetLastNonEmptyLine(), status})
+		cells := map[string]string{
+			"command / process": proc.Shortname,
+			"output":            proc.GetLastNonEmptyLine(),
+			"matches":           renderMatches(proc),
+			"status":            status,
+		}
+		if procstat.Supported {
+			usage := renderUsage(proc, vm)
+			cells["cpu"], cells["mem"], cells["trend"] = usage[0], usage[1], usage[2]
+		}
+
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = cells[column]
+		}
+		table.AddRow(row)
+	}
+
+	return table
+}
+
+// renderMatches renders how many lines matched a trigger, in the color of
+// the most severe one
+func renderMatches(proc *process.Process) string {
+	count, severity := proc.TriggerMatches()
+	if count == 0 {
+		return ""
 	}
 
 	return lipgloss.NewStyle().
-		MaxHeight(height).
-		Height(height).
-		Render(table.Render())
+		Foreground(severityColors[severity]).
+		Bold(true).
+		Render(fmt.Sprintf("%d", count))
+}
+
+// usageTrendWidth is the width of the sparkline of the memory use
+const usageTrendWidth = 12
+
+var (
+	sparks       = []rune("▁▂▃▄▅▆▇█")
+	warningStyle lipgloss.Style
+)
+
+// renderUsage renders the cpu, mem and trend cells of a process, in red when
+// it uses more than the configured warning thresholds
+func renderUsage(proc *process.Process, vm types.Model) []string {
+	samples := proc.Usage()
+	if len(samples) == 0 || proc.Pid() == 0 {
+		return []string{"", "", ""}
+	}
+
+	last := samples[len(samples)-1]
+	cpu := fmt.Sprintf("%.0f%%", last.CPU)
+	mem := formatBytes(last.RSS)
+
+	if last.CPU >= vm.CPUWarning {
+		cpu = warningStyle.Render(cpu)
+	}
+
+	trend := sparkline(samples)
+	if vm.MemoryWarning > 0 && last.RSS >= uint64(vm.MemoryWarning) {
+		mem = warningStyle.Render(mem)
+		trend = warningStyle.Render(trend)
+	}
+
+	return []string{cpu, mem, trend}
+}
+
+// sparkline draws the memory use of the samples relative to the highest one,
+// so that a process that keeps growing stands out
+func sparkline(samples []process.UsageSample) string {
+	var peak uint64
+	for _, s := range samples {
+		peak = max(peak, s.RSS)
+	}
+
+	var b strings.Builder
+	for _, s := range samples {
+		level := 0
+		if peak > 0 {
+			level = int(s.RSS * uint64(len(sparks)-1) / peak)
+		}
+		b.WriteRune(sparks[level])
+	}
+
+	return b.String()
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	value, suffix := float64(n)/unit, "K"
+	for _, next := range []string{"M", "G", "T"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+
+	if value < 10 {
+		return fmt.Sprintf("%.1f%s", value, suffix)
+	}
+	return fmt.Sprintf("%.0f%s", value, suffix)
 }
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+var paletteSelectedStyle lipgloss.Style
+
+// paletteWidth is the width of the command palette when the window allows
+const paletteWidth = 60
+
+// renderPalette renders the command palette, its input above the entries
+// that match it, scrolled so the selected one is in sight
+func renderPalette(vm types.Model, width, maxLines int) string {
+	width = min(width, paletteWidth)
+	rows := max(1, maxLines-6)
+
+	start := 0
+	if vm.PaletteSelected >= rows {
+		start = vm.PaletteSelected - rows + 1
+	}
+	end := min(len(vm.PaletteEntries), start+rows)
+
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render("command palette") + "\n\n")
+	b.WriteString(lipgloss.NewStyle().Width(width).Render(vm.InputLine) + "\n\n")
+
+	if len(vm.PaletteEntries) == 0 {
+		b.WriteString(helpDescStyle.Render("nothing matches"))
+	}
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteString("\n")
+		}
+		if i == vm.PaletteSelected {
+			b.WriteString(paletteSelectedStyle.Render("› " + vm.PaletteEntries[i]))
+		} else {
+			b.WriteString("  " + vm.PaletteEntries[i])
+		}
+	}
+
+	b.WriteString("\n\n" + helpDescStyle.Render("↑/↓ select, ↵ run, esc close"))
+	return lipgloss.NewStyle().Width(width).Render(b.String())
+}
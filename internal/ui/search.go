@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/types"
+)
+
+var selectionHintStyle lipgloss.Style
+
+// renderHint renders the hint line of a tab that shows output, which turns
+// into the search input while a query is typed
+func renderHint(vm types.Model, width int, text string) string {
+	if vm.Searching || vm.Prompting {
+		return lipgloss.NewStyle().Width(width).Render(vm.InputLine)
+	}
+
+	k := vm.Keys
+	if vm.Selecting {
+		return selectionHintStyle.Width(width).Render("selecting lines, " + hints(
+			item("extend", k.Up, k.Down, k.PageUp, k.PageDown),
+			item("copy", k.Copy),
+			item("cancel", k.Cancel),
+		))
+	}
+
+	if vm.CopyStatus != "" {
+		text = vm.CopyStatus + " · " + text
+	}
+
+	if vm.Search != "" {
+		status := vm.SearchStatus
+		if status == "" {
+			status = "no matches"
+		}
+		text = fmt.Sprintf("/%s %s, %s · ", vm.Search, status, hints(
+			item("older/newer", k.NextMatch, k.PrevMatch),
+			item("clear", k.Cancel),
+		)) + text
+	}
+
+	return hintStyle.Width(width).Render(text)
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/types"
+	"github.com/thejawker/rennen/internal/utils"
+)
+
+var (
+	sidebarStyle       lipgloss.Style
+	sidebarActiveStyle lipgloss.Style
+	sidebarWindowStyle lipgloss.Style
+)
+
+// sidebarWidth is the width the sidebar takes from the window, which is 0
+// outside of the sidebar layout
+func sidebarWidth(vm types.Model) int {
+	if vm.Layout != types.LayoutSidebar {
+		return 0
+	}
+	return min(24, max(16, vm.WindowSize.Width/4))
+}
+
+// renderSidebar lists the tabs down the left side of the window, one per
+// line with the number that jumps to it and an icon of its status
+func renderSidebar(m types.ViewModelProvider, height int) string {
+	vm := m.GetViewModel()
+	width := sidebarWidth(vm) - sidebarStyle.GetHorizontalBorderSize()
+	innerWidth := width - sidebarStyle.GetHorizontalPadding()
+	rows := max(1, height-sidebarStyle.GetVerticalFrameSize())
+
+	first, last := sidebarRange(vm, rows)
+
+	var lines []string
+	for i := first; i <= last; i++ {
+		t := vm.Tabs[i]
+
+		number := jumpKey(vm, i)
+		if number == "" {
+			number = " "
+		}
+
+		icon, color := tabIcon(m, t)
+		label := utils.SmartTruncate(decorateTabName(t, t.Name), max(1, innerWidth-4), "")
+
+		style := lipgloss.NewStyle().Foreground(colors.Text)
+		if i == vm.ActiveTab {
+			style = sidebarActiveStyle
+		}
+		if t.Alert != "" {
+			style = style.Foreground(severityColors[t.Alert]).Bold(true)
+		}
+
+		line := overflowStyle.Render(number) + " " +
+			lipgloss.NewStyle().Foreground(color).Render(icon) + " " +
+			style.Render(label)
+		lines = append(lines, line)
+	}
+
+	// show that there are more tabs above or below, in place of the tab at
+	// the edge
+	if first > 0 && len(lines) > 1 {
+		lines[0] = overflowStyle.Render("↑ " + strconv.Itoa(first+1) + " more")
+	}
+	if last < len(vm.Tabs)-1 && len(lines) > 1 {
+		lines[len(lines)-1] = overflowStyle.Render("↓ " + strconv.Itoa(len(vm.Tabs)-last) + " more")
+	}
+
+	return sidebarStyle.
+		Width(width).
+		Height(rows).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// sidebarRange picks the tabs the sidebar has room for, keeping the active
+// tab in sight
+func sidebarRange(vm types.Model, rows int) (first, last int) {
+	heights := make([]int, len(vm.Tabs))
+	for i := range heights {
+		heights[i] = 1
+	}
+	return visibleRange(heights, vm.ActiveTab, rows)
+}
+
+// tabIcon shows what a tab is, or for a process whether it's running and how
+// it ended
+func tabIcon(m types.ViewModelProvider, t types.Tab) (string, lipgloss.TerminalColor) {
+	switch t.Kind {
+	case types.TabOverview:
+		return "◆", colors.Accent
+	case types.TabLogs:
+		return "≡", colors.Subtle
+	case types.TabView:
+		return "▦", colors.Subtle
+	}
+
+	p := m.GetProcessByName(t.Name)
+	if p == nil {
+		return "?", colors.Faint
+	}
+
+	switch p.State() {
+	case "running":
+		return "●", colors.Success
+	case "stopped":
+		return "■", colors.Subtle
+	case "exited":
+		if code, _ := p.LastExitCode(); code != 0 {
+			return "✗", colors.Error
+		}
+		return "✓", colors.Success
+	}
+	return "○", colors.Faint
+}
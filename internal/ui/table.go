@@ -5,12 +5,6 @@ import (
 )
 import "github.com/charmbracelet/lipgloss/table"
 
-const (
-	gray        = lipgloss.Color("245")
-	lightGray   = lipgloss.Color("241")
-	lighterGray = lipgloss.Color("253")
-)
-
 type Table struct {
 	Columns      []string
 	Rows         [][]string
@@ -52,15 +46,15 @@ func (t *Table) SetColumnWidth(column string, width int) *Table {
 func (t *Table) Render() string {
 	var (
 		// HeaderStyle is the lipgloss style used for the table headers.
-		HeaderStyle = lipgloss.NewStyle().Foreground(gray).Bold(true).Align(lipgloss.Left)
+		HeaderStyle = lipgloss.NewStyle().Foreground(colors.TableHeader).Bold(true).Align(lipgloss.Left)
 		// CellStyle is the base lipgloss style used for the table rows.
 		CellStyle = lipgloss.NewStyle()
 		// OddRowStyle is the lipgloss style used for odd-numbered table rows.
-		OddRowStyle = CellStyle.Foreground(gray)
+		OddRowStyle = CellStyle.Foreground(colors.TableRow)
 		// EvenRowStyle is the lipgloss style used for even-numbered table rows.
-		EvenRowStyle = CellStyle.Foreground(lightGray)
+		EvenRowStyle = CellStyle.Foreground(colors.TableRowAlt)
 		// BorderStyle is the lipgloss style used for the table border.
-		BorderStyle = lipgloss.NewStyle().Foreground(lighterGray)
+		BorderStyle = lipgloss.NewStyle().Foreground(colors.TableBorder)
 	)
 
 	instance := table.New().
@@ -88,6 +82,22 @@ func (t *Table) Render() string {
 	return instance.Render()
 }
 
+// RowHeights returns the number of lines each row takes up once its cells
+// are wrapped to the widths of their columns
+func (t *Table) RowHeights() []int {
+	heights := make([]int, len(t.Rows))
+	for i, row := range t.Rows {
+		heights[i] = 1
+		for col, cell := range row {
+			if col < len(t.Columns) {
+				cell = lipgloss.NewStyle().Width(t.getColumnWidth(t.Columns[col])).Render(cell)
+			}
+			heights[i] = max(heights[i], lipgloss.Height(cell))
+		}
+	}
+	return heights
+}
+
 func (t *Table) getColumnWidth(column string) int {
 	// if the width is set, return it
 	if width, ok := t.ColumnWidths[column]; ok {
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/loglevel"
+	"github.com/thejawker/rennen/internal/theme"
+)
+
+// colors is the theme everything is drawn with
+var colors theme.Theme
+
+func init() {
+	t, _ := theme.Builtin(theme.Default)
+	SetTheme(t)
+}
+
+// SetTheme restyles everything with the colors of a theme. a theme without
+// colors uses reverse video and bold to keep what matters visible.
+func SetTheme(t theme.Theme) {
+	colors = t
+
+	highlightColor = t.Border
+	inactiveTabStyle = lipgloss.NewStyle().Border(inactiveTabBorder, true).BorderForeground(highlightColor).Padding(0, 1)
+	activeTabStyle = inactiveTabStyle.Border(activeTabBorder, true)
+	compactTabStyle = lipgloss.NewStyle().Foreground(t.Subtle).Padding(0, 1)
+	compactActiveTab = compactTabStyle.Foreground(t.Contrast).Background(t.Accent).Bold(true)
+	windowStyle = lipgloss.NewStyle().BorderForeground(highlightColor).Padding(0, 1, 0, 1).Align(lipgloss.Top, lipgloss.Left).Border(lipgloss.RoundedBorder()).UnsetBorderTop()
+	sidebarWindowStyle = windowStyle.BorderTop(true)
+	overflowStyle = lipgloss.NewStyle().Foreground(t.Subtle)
+
+	sidebarStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(highlightColor).Padding(0, 1)
+	sidebarActiveStyle = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	bannerStyle = lipgloss.
+		NewStyle().
+		Foreground(t.Contrast).
+		Background(t.Banner).
+		Padding(0, 1)
+	replayBannerStyle = bannerStyle.Background(t.ReplayBanner)
+
+	insertColor = t.Accent
+	insertStyle = lipgloss.
+		NewStyle().
+		Foreground(t.Contrast).
+		Background(insertColor).
+		Bold(true).
+		Padding(0, 1)
+
+	severityColors = map[string]lipgloss.TerminalColor{
+		"info":    t.Info,
+		"warning": t.Warning,
+		"error":   t.Error,
+	}
+
+	hintStyle = lipgloss.
+		NewStyle().
+		Foreground(t.Subtle).
+		Align(lipgloss.Bottom, lipgloss.Right)
+	selectionHintStyle = hintStyle.Foreground(insertColor).Bold(true)
+
+	paneStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(highlightColor).Padding(0, 1)
+	focusedPaneStyle = paneStyle.BorderForeground(t.Accent)
+	paneStatusStyle = lipgloss.NewStyle().Foreground(t.Subtle)
+
+	outputStyle = lipgloss.NewStyle().Foreground(t.Text)
+	markerStyle = lipgloss.NewStyle().Foreground(t.Subtle).Italic(true)
+	timestampStyle = lipgloss.NewStyle().Foreground(t.Faint)
+	stderrGutter = lipgloss.NewStyle().Foreground(t.Error).Render("▌")
+
+	levelStyles = map[loglevel.Level]lipgloss.Style{
+		loglevel.Trace: outputStyle.Foreground(t.Faint),
+		loglevel.Debug: outputStyle.Foreground(t.Faint),
+		loglevel.Warn:  outputStyle.Foreground(t.Warning),
+		loglevel.Error: outputStyle.Foreground(t.Error),
+		loglevel.Fatal: outputStyle.Foreground(t.Error).Bold(true),
+	}
+
+	matchStyle = lipgloss.
+		NewStyle().
+		Foreground(t.MatchText).
+		Background(t.Match)
+	currentMatchStyle = matchStyle.Background(t.CurrentMatch).Bold(true)
+	selectedStyle = lipgloss.
+		NewStyle().
+		Foreground(t.Contrast).
+		Background(t.Selection)
+
+	jsonKeyStyle = lipgloss.NewStyle().Foreground(t.JSONKey)
+	jsonValueStyle = outputStyle
+
+	warningStyle = lipgloss.NewStyle().Foreground(t.Error).Bold(true)
+	paletteSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(insertColor)
+	helpTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(insertColor)
+	helpDescStyle = lipgloss.NewStyle().Foreground(t.Text)
+
+	if t.Plain {
+		bannerStyle = bannerStyle.Reverse(true)
+		replayBannerStyle = bannerStyle
+		insertStyle = insertStyle.Reverse(true)
+		compactActiveTab = compactActiveTab.Reverse(true)
+		sidebarActiveStyle = sidebarActiveStyle.Reverse(true)
+		levelStyles[loglevel.Error] = levelStyles[loglevel.Error].Bold(true)
+		levelStyles[loglevel.Trace] = levelStyles[loglevel.Trace].Faint(true)
+		levelStyles[loglevel.Debug] = levelStyles[loglevel.Debug].Faint(true)
+		matchStyle = matchStyle.Reverse(true)
+		currentMatchStyle = matchStyle.Bold(true).Underline(true)
+		selectedStyle = selectedStyle.Reverse(true)
+	}
+}
@@ -3,10 +3,12 @@ package ui
 import (
 	"fmt"
 	"github.com/thejawker/rennen/internal/utils"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/loglevel"
 	"github.com/thejawker/rennen/internal/types"
 )
 
@@ -14,15 +16,26 @@ var (
 	inactiveTabBorder = tabBorderWithBottom("┴", "─", "┴")
 	activeTabBorder   = tabBorderWithBottom("┘", " ", "└")
 	docStyle          = lipgloss.NewStyle().Padding(0, 0, 0, 0)
-	highlightColor    = lipgloss.AdaptiveColor{Light: "#656575", Dark: "#475569"}
-	inactiveTabStyle  = lipgloss.NewStyle().Border(inactiveTabBorder, true).BorderForeground(highlightColor).Padding(0, 1)
-	activeTabStyle    = inactiveTabStyle.Border(activeTabBorder, true)
-	windowStyle       = lipgloss.NewStyle().BorderForeground(highlightColor).Padding(0, 1, 0, 1).Align(lipgloss.Top, lipgloss.Left).Border(lipgloss.RoundedBorder()).UnsetBorderTop()
-
-	hintStyle = lipgloss.
-			NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#a7a7a7", Dark: "#8a8a8a"}).
-			Align(lipgloss.Bottom, lipgloss.Right)
+
+	// compactWindowStyle has no frame, so the output gets the whole terminal
+	compactWindowStyle = lipgloss.NewStyle()
+
+	// the styles below take their colors from the theme, see SetTheme
+	highlightColor    lipgloss.TerminalColor
+	inactiveTabStyle  lipgloss.Style
+	activeTabStyle    lipgloss.Style
+	compactTabStyle   lipgloss.Style
+	compactActiveTab  lipgloss.Style
+	windowStyle       lipgloss.Style
+	overflowStyle     lipgloss.Style
+	bannerStyle       lipgloss.Style
+	replayBannerStyle lipgloss.Style
+	insertColor       lipgloss.TerminalColor
+	insertStyle       lipgloss.Style
+	hintStyle         lipgloss.Style
+
+	// severityColors are the colors of the severities of triggers
+	severityColors map[string]lipgloss.TerminalColor
 )
 
 func tabBorderWithBottom(left, middle, right string) lipgloss.Border {
@@ -36,50 +49,106 @@ func tabBorderWithBottom(left, middle, right string) lipgloss.Border {
 func RenderView(m types.ViewModelProvider) string {
 	doc := strings.Builder{}
 
-	// Render tabs
-	doc.WriteString(renderTabs(m.GetViewModel()))
-	doc.WriteString("\n")
+	// Render the config error banner, if the last reload failed
+	bannerHeight := 0
+	if banner := renderBanner(m.GetViewModel()); banner != "" {
+		doc.WriteString(banner)
+		doc.WriteString("\n")
+		bannerHeight = lipgloss.Height(banner)
+	}
+
+	// Render tabs, which the sidebar layout lists next to the window instead
+	vm := m.GetViewModel()
+	tabsHeight := 0
+	if vm.Layout != types.LayoutSidebar {
+		tabs := renderTabs(vm)
+		if vm.Layout == types.LayoutCompact {
+			tabs = renderCompactTabs(vm)
+		}
+		doc.WriteString(tabs)
+		doc.WriteString("\n")
+		tabsHeight = lipgloss.Height(tabs)
+	}
 
 	// window style
-	windowWidth := m.GetViewModel().WindowSize.Width - windowStyle.GetHorizontalFrameSize() + 2
-	windowHeight := m.GetViewModel().WindowSize.Height - activeTabStyle.GetVerticalFrameSize() - 2
+	frame := frameStyle(vm)
+	windowWidth := vm.WindowSize.Width - frame.GetHorizontalBorderSize() - sidebarWidth(vm)
+	windowHeight := vm.WindowSize.Height - tabsHeight - frame.GetVerticalFrameSize() - bannerHeight
 
 	// Render content
 	content, shouldCenter := renderContent(m, windowHeight)
 
-	ws := windowStyle.Width(windowWidth).Height(windowHeight)
+	ws := frame.Width(windowWidth).Height(windowHeight)
+
+	// make it obvious that keys go to the process instead of ren
+	if m.GetViewModel().InsertMode != types.InsertNone {
+		ws = ws.BorderForeground(insertColor)
+	}
 
 	if shouldCenter {
 		ws = ws.Align(lipgloss.Center, lipgloss.Center)
 	}
 
+	if vm.Layout == types.LayoutSidebar {
+		sidebar := renderSidebar(m, vm.WindowSize.Height-bannerHeight)
+		doc.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, sidebar, ws.Render(content)))
+		return doc.String()
+	}
+
 	doc.WriteString(ws.Render(content))
 
 	return doc.String()
 }
 
-func renderTabs(vm types.Model) string {
-	var renderedTabs []string
+// frameStyle is the style of the window around the content, which the
+// compact layout leaves out and the sidebar layout closes at the top
+func frameStyle(vm types.Model) lipgloss.Style {
+	switch vm.Layout {
+	case types.LayoutCompact:
+		return compactWindowStyle
+	case types.LayoutSidebar:
+		return sidebarWindowStyle
+	}
+	return windowStyle
+}
 
-	// Calculate total width available for tabs
-	totalWidth := vm.WindowSize.Width - (len(vm.Tabs) * 2) // Subtract space for borders between tabs
+// contentWidth is the width the content of the window can use
+func contentWidth(vm types.Model) int {
+	return vm.WindowSize.Width - sidebarWidth(vm) - frameStyle(vm).GetHorizontalFrameSize() - 2
+}
 
-	// Calculate the width for each tab
-	tabWidth := totalWidth / len(vm.Tabs)
+func renderBanner(vm types.Model) string {
+	if vm.Replay != "" {
+		return replayBannerStyle.
+			Width(vm.WindowSize.Width).
+			MaxHeight(1).
+			Render(vm.Replay)
+	}
 
-	// Adjust in case of rounding issues
-	extraSpace := totalWidth % len(vm.Tabs)
+	if vm.ConfigError == "" {
+		return ""
+	}
 
-	for i, t := range vm.Tabs {
-		var style lipgloss.Style
-		isFirst, isLast, isActive := i == 0, i == len(vm.Tabs)-1, i == vm.ActiveTab
+	message := "config not reloaded, still running the previous one: " + vm.ConfigError
 
-		// Give extra space to some tabs if there's leftover width
-		widthAdjustment := tabWidth
-		if extraSpace > 0 {
-			widthAdjustment++
-			extraSpace--
-		}
+	return bannerStyle.
+		Width(vm.WindowSize.Width).
+		MaxHeight(2).
+		Render(message)
+}
+
+func renderTabs(vm types.Model) string {
+	var renderedTabs []string
+
+	strip := layoutTabs(vm)
+	for i := strip.first; i <= strip.last; i++ {
+		t := vm.Tabs[i]
+		var style lipgloss.Style
+		// with tabs scrolled out of view the indicators take the corners
+		isFirst := i == 0 && !strip.overflow
+		isLast := i == len(vm.Tabs)-1 && !strip.overflow
+		isActive := i == vm.ActiveTab
+		widthAdjustment := strip.widths[i-strip.first]
 
 		if isActive {
 			style = activeTabStyle.Width(widthAdjustment)
@@ -108,78 +177,391 @@ func renderTabs(vm types.Model) string {
 		style = style.
 			Border(border)
 
-		tabName := t.Name
-		if t.Notification {
-			tabName = "● " + tabName
-		}
-
-		if t.Status != "" {
-			tabName = tabName + "(" + t.Status + ")"
+		if t.Alert != "" {
+			style = style.Foreground(severityColors[t.Alert]).Bold(true)
 		}
 
-		truncatedName := utils.SmartTruncate(tabName, widthAdjustment-2, "")
+		truncatedName := utils.SmartTruncate(numberedLabel(vm, i), widthAdjustment-2, "")
 
 		renderedTabs = append(renderedTabs, style.Render(truncatedName))
 	}
 
+	if strip.overflow {
+		left := overflowIndicator(strip.first > 0, "‹", "├")
+		right := overflowIndicator(strip.last < len(vm.Tabs)-1, "›", "┤")
+		renderedTabs = append(append([]string{left}, renderedTabs...), right)
+	}
+
 	return lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
 }
 
+// overflowIndicator is the column next to the tabs that shows there are more
+// of them that way, ending in the corner of the window below
+func overflowIndicator(more bool, arrow, corner string) string {
+	if !more {
+		arrow = " "
+	}
+	return " \n" + overflowStyle.Render(arrow) + "\n" + lipgloss.NewStyle().Foreground(highlightColor).Render(corner)
+}
+
+// maxTabWidth keeps long names from taking up the tab bar when not all tabs
+// fit
+const maxTabWidth = 24
+
+// tabStrip is the part of the tabs that is shown in the tab bar
+type tabStrip struct {
+	// first and last are the tabs that are shown
+	first, last int
+	// widths are the widths of the tabs that are shown, without borders
+	widths []int
+	// overflow is set when not all tabs fit, which shows ‹ and › at the
+	// sides
+	overflow bool
+}
+
+// layoutTabs works out which tabs the tab bar shows. when every name fits
+// the window is divided evenly over the tabs, otherwise the tabs get the
+// width of their names and scroll to keep the active tab in sight.
+func layoutTabs(vm types.Model) tabStrip {
+	frame := inactiveTabStyle.GetHorizontalFrameSize()
+
+	natural := make([]int, len(vm.Tabs))
+	total := 0
+	for i := range vm.Tabs {
+		natural[i] = min(lipgloss.Width(numberedLabel(vm, i)), maxTabWidth) + frame
+		total += natural[i]
+	}
+
+	if total <= vm.WindowSize.Width {
+		even := tabWidths(vm)
+		for i := range even {
+			if even[i]+inactiveTabStyle.GetHorizontalBorderSize() < natural[i] {
+				return tabStrip{first: 0, last: len(vm.Tabs) - 1, widths: spread(natural, 0, len(vm.Tabs)-1, vm.WindowSize.Width)}
+			}
+		}
+		return tabStrip{first: 0, last: len(vm.Tabs) - 1, widths: even}
+	}
+
+	// leave room for the indicators on both sides
+	available := vm.WindowSize.Width - 2
+	first, last := visibleRange(natural, vm.ActiveTab, available)
+
+	return tabStrip{first: first, last: last, widths: spread(natural, first, last, available), overflow: true}
+}
+
+// spread gives the tabs from first to last their natural width plus an even
+// part of the space that is left over, and returns their widths without
+// borders
+func spread(natural []int, first, last, available int) []int {
+	border := inactiveTabStyle.GetHorizontalBorderSize()
+
+	used := 0
+	for _, width := range natural[first : last+1] {
+		used += width
+	}
+
+	shown := last - first + 1
+	extra := max(0, available-used)
+	widths := make([]int, shown)
+	for i := range widths {
+		widths[i] = natural[first+i] - border + extra/shown
+		if i < extra%shown {
+			widths[i]++
+		}
+		widths[i] = max(1, min(widths[i], available-border))
+	}
+
+	return widths
+}
+
+// visibleRange picks the run of items around the active one that fits in the
+// available space, growing on both sides so the active one stays near the
+// middle
+func visibleRange(sizes []int, active, available int) (first, last int) {
+	active = max(0, min(active, len(sizes)-1))
+	first, last = active, active
+	used := sizes[active]
+
+	for {
+		grew := false
+		if last+1 < len(sizes) && used+sizes[last+1] <= available {
+			last++
+			used += sizes[last]
+			grew = true
+		}
+		if first > 0 && used+sizes[first-1] <= available {
+			first--
+			used += sizes[first]
+			grew = true
+		}
+		if !grew {
+			return first, last
+		}
+	}
+}
+
+// tabWidths divides the width of the window over the tabs, leaving room for
+// their borders
+func tabWidths(vm types.Model) []int {
+	// Calculate total width available for tabs
+	totalWidth := vm.WindowSize.Width - (len(vm.Tabs) * 2) // Subtract space for borders between tabs
+
+	// Calculate the width for each tab
+	tabWidth := totalWidth / len(vm.Tabs)
+
+	// Adjust in case of rounding issues
+	extraSpace := totalWidth % len(vm.Tabs)
+
+	widths := make([]int, len(vm.Tabs))
+	for i := range widths {
+		// Give extra space to some tabs if there's leftover width
+		widths[i] = tabWidth
+		if extraSpace > 0 {
+			widths[i]++
+			extraSpace--
+		}
+	}
+
+	return widths
+}
+
+// renderCompactTabs renders the tabs on a single line without borders, for
+// the compact layout
+func renderCompactTabs(vm types.Model) string {
+	tabs, first, last, overflow := compactTabs(vm)
+	if overflow {
+		tabs = tabs[first : last+1]
+		left := overflowStyle.Render(compactIndicator(first > 0, "‹"))
+		right := overflowStyle.Render(compactIndicator(last < len(vm.Tabs)-1, "›"))
+		tabs = append(append([]string{left}, tabs...), right)
+	}
+
+	return lipgloss.NewStyle().MaxWidth(vm.WindowSize.Width).Render(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+}
+
+// compactIndicator is the indicator next to the compact tabs, which takes
+// the same space whether there are more tabs that way or not
+func compactIndicator(more bool, arrow string) string {
+	if !more {
+		arrow = " "
+	}
+	return " " + arrow + " "
+}
+
+// compactTabs renders every tab of the compact layout on its own, and picks
+// the ones that are shown when they don't all fit
+func compactTabs(vm types.Model) (tabs []string, first, last int, overflow bool) {
+	widths := make([]int, len(vm.Tabs))
+	total := 0
+	for i, t := range vm.Tabs {
+		style := compactTabStyle
+		if i == vm.ActiveTab {
+			style = compactActiveTab
+		}
+		if t.Alert != "" {
+			style = style.Foreground(severityColors[t.Alert]).Bold(true)
+		}
+
+		label := numberedLabel(vm, i)
+		if lipgloss.Width(label) > maxTabWidth {
+			label = utils.SmartTruncate(label, maxTabWidth, "")
+		}
+		tabs = append(tabs, style.Render(label))
+		widths[i] = lipgloss.Width(tabs[i])
+		total += widths[i]
+	}
+
+	if total <= vm.WindowSize.Width {
+		return tabs, 0, len(tabs) - 1, false
+	}
+
+	indicators := 2 * lipgloss.Width(compactIndicator(true, "‹"))
+	first, last = visibleRange(widths, vm.ActiveTab, vm.WindowSize.Width-indicators)
+	return tabs, first, last, true
+}
+
+// jumpKey is the key that jumps to the process of a tab, if it has one
+func jumpKey(vm types.Model, i int) string {
+	n := types.ProcessNumber(vm.Tabs, i)
+	keys := vm.Keys.Jump.Keys()
+	if n == 0 || n > len(keys) || !vm.Keys.Jump.Enabled() {
+		return ""
+	}
+	return keys[n-1]
+}
+
+// numberedLabel is the label of a tab in the tab bar, starting with the key
+// that jumps to it
+func numberedLabel(vm types.Model, i int) string {
+	if key := jumpKey(vm, i); key != "" {
+		return key + " " + tabLabel(vm.Tabs[i])
+	}
+	return tabLabel(vm.Tabs[i])
+}
+
+// tabLabel is the text of a tab, with what it needs attention for
+func tabLabel(t types.Tab) string {
+	tabName := t.Name
+	if t.Kind == types.TabView {
+		tabName = "▦ " + tabName
+	}
+	return decorateTabName(t, tabName)
+}
+
+// decorateTabName adds what a tab needs attention for to its name
+func decorateTabName(t types.Tab, tabName string) string {
+	if t.Notification {
+		tabName += unreadBadges(t)
+	}
+	if t.Alert != "" {
+		tabName = "! " + tabName
+	}
+
+	if t.Status != "" {
+		tabName = tabName + "(" + t.Status + ")"
+	}
+
+	return tabName
+}
+
+// unreadBadges shows the number of lines and errors that came in since the
+// tab was last looked at
+func unreadBadges(t types.Tab) string {
+	count := func(n int) string {
+		if n > 999 {
+			return "999+"
+		}
+		return strconv.Itoa(n)
+	}
+
+	badges := ""
+	if t.Unread > 0 {
+		badges += " •" + count(t.Unread)
+	}
+	if t.UnreadErrors > 0 {
+		badges += " ✗" + count(t.UnreadErrors)
+	}
+
+	return badges
+}
+
 func renderContent(m types.ViewModelProvider, maxLines int) (string, bool) {
+	if vm := m.GetViewModel(); vm.PaletteOpen {
+		return renderPalette(vm, contentWidth(vm), maxLines), true
+	} else if vm.ShowHelp {
+		return renderHelp(vm, contentWidth(vm)), true
+	}
+
 	if m.IsOverview() {
 		return renderOverview(m, maxLines), false
 	}
 
+	vm := m.GetViewModel()
+	tab := vm.Tabs[vm.ActiveTab]
+	switch tab.Kind {
+	case types.TabView:
+		windowWidth := contentWidth(vm)
+		return renderGrid(m, tab, windowWidth, maxLines), false
+	case types.TabLogs:
+		windowWidth := contentWidth(vm)
+		return renderLogs(m, tab, windowWidth, maxLines), false
+	}
+
 	process := m.GetActiveProcess()
 	if process == nil {
 		return fmt.Sprintf("Viewing tab: %s", m.GetActiveTabName()), true
 	}
 
-	windowWidth := m.GetViewModel().WindowSize.Width - windowStyle.GetHorizontalFrameSize() - 2
+	windowWidth := contentWidth(vm)
 
-	// Define adaptive styles for header and output
+	// Define styles for header and output
 	commandStyle := lipgloss.
 		NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#a7c7e7", Dark: "#8394a7"}).
+		Foreground(colors.Command).
 		Bold(true)
 	descriptionStyle := lipgloss.
 		NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#a7e7a7", Dark: "#8aa78a"})
+		Foreground(colors.Description)
 	dividerStyle := lipgloss.
 		NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#d3d3d3", Dark: "#5c5c5c"}).
+		Foreground(colors.Divider).
 		PaddingTop(0).
 		PaddingBottom(1)
-	outputStyle := lipgloss.
-		NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#606060", Dark: "#e0e0e0"})
 
 	// Construct the window content with command, description, and output
 	header := commandStyle.Render(fmt.Sprintf("$ %s", process.Command)) + "\n"
 	header += descriptionStyle.Render(fmt.Sprintf("%s", process.Description)) + "\n"
 
 	divider := dividerStyle.Render(strings.Repeat("─", windowWidth))
-	output := process.GetOutput()
 
-	if output == "" {
-		output = "No output yet..."
+	// Render hint, left right tab,
+	k := vm.Keys
+	hintText := hints(
+		item("tabs", k.PrevTab, k.NextTab),
+		item("scroll", k.Up, k.Down),
+		item("clear", k.Clear),
+		item("close", k.Close),
+		item("restart", k.Restart),
+		item("input", k.Insert),
+		item("input line", k.InsertLine),
+		item("grid", k.Grid),
+		item("timestamps", k.Timestamps),
+		item("warnings", k.Warnings),
+		item("expand json", k.Expand),
+		item("search", k.Search),
+		item("select", k.Select),
+		item("copy", k.Copy, k.CopyLast, k.CopyAll),
+		item("help", k.Help),
+		item("quit all", k.Quit),
+	)
+	if tab.MinLevel != loglevel.Unknown {
+		hintText = fmt.Sprintf("only warnings and errors, %s to show all · ", keyName(k.Warnings)) + hintText
+	}
+	if !tab.Scroll.Following() {
+		hintText = fmt.Sprintf("%d lines below, %s to follow · ", tab.Scroll.Offset, keyName(k.Bottom)) + hintText
+	}
+	hint := renderHint(vm, windowWidth, hintText)
+	if vm.InsertMode != types.InsertNone {
+		hint = renderInsertHint(vm, process.Shortname, windowWidth)
 	}
 
 	// Calculate available height for viewport
 	headerHeight := lipgloss.Height(header)
 	dividerHeight := lipgloss.Height(divider)
-	viewportHeight := maxLines - headerHeight - dividerHeight - 1
+	viewportHeight := maxLines - headerHeight - dividerHeight - lipgloss.Height(hint)
 
 	// Create a viewport for scrollable content
 	vp := viewport.New(windowWidth, viewportHeight)
-	vp.SetContent(outputStyle.Render(output))
+	lines := process.TailAtLeast(viewportHeight+tab.Scroll.Offset, tab.MinLevel)
+	if len(lines) == 0 && tab.MinLevel != loglevel.Unknown {
+		vp.SetContent(outputStyle.Render("No warnings or errors yet..."))
+	} else if len(lines) == 0 {
+		vp.SetContent(outputStyle.Render("No output yet..."))
+	} else {
+		vp.SetContent(renderLines(lines, vm))
+	}
 	vp.GotoBottom()
-
-	// Render hint, left right tab,
-	hint := hintStyle.Width(windowWidth).Render("←/→ tabs, (q)uit all, (c)lear, (x) close, (r)eload")
+	vp.LineUp(tab.Scroll.Offset)
 
 	// Combine all elements
 	content := fmt.Sprintf("%s\n%s\n%s\n%s", header, divider, vp.View(), hint)
 
 	return content, false
 }
+
+// renderInsertHint replaces the hint line while attached to a process
+func renderInsertHint(vm types.Model, name string, width int) string {
+	label := "INSERT"
+	help := "keys go to " + name + ", ctrl+] to leave"
+	if vm.InsertMode == types.InsertLine {
+		label = "LINE"
+		help = "↵ sends the line to " + name + ", esc or ctrl+] to leave"
+	}
+
+	status := insertStyle.Render(label) + " " + hintStyle.Render(help)
+	if vm.InsertMode != types.InsertLine {
+		return lipgloss.NewStyle().Width(width).Render(status)
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(vm.InputLine) + "\n" + lipgloss.NewStyle().Width(width).Render(status)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"regexp"
 	"strings"
 	"time"
@@ -65,7 +66,7 @@ func StripTerminalReturns(s string) string {
 	// \x1b[1G - move to beginning of line
 
 	ansiEscape := regexp.MustCompile(`\x1b\[(2K|0G|1G)`) // Regex to remove ANSI escape codes
-	controlChars := regexp.MustCompile(`[\r\\b]`)        // Regex to remove control characters
+	controlChars := regexp.MustCompile(`[\r\x08]`)       // Regex to remove carriage returns and backspaces
 
 	s = ansiEscape.ReplaceAllString(s, "")
 	s = controlChars.ReplaceAllString(s, "")
@@ -73,9 +74,72 @@ func StripTerminalReturns(s string) string {
 	return s
 }
 
+// ansiSequence matches CSI sequences like colors and cursor movement, and OSC
+// sequences like window titles and hyperlinks
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// StripAnsi removes all ANSI escape sequences from s, leaving plain text
+func StripAnsi(s string) string {
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
 func RelativeTime(t time.Time) string {
 	if t.IsZero() {
 		return ""
 	}
 	return time.Since(t).Round(time.Second).String()
 }
+
+// FindJSONObject returns where the JSON object that makes up the rest of the
+// line starts, or -1 when there is none. unless anywhere is set the object
+// has to be the whole line.
+func FindJSONObject(line string, anywhere bool) int {
+	trimmed := strings.TrimRight(line, " \t\r")
+	if !strings.HasSuffix(trimmed, "}") {
+		return -1
+	}
+
+	start := len(trimmed) - len(strings.TrimLeft(trimmed, " \t"))
+	if anywhere {
+		start = strings.IndexByte(trimmed, '{')
+	}
+
+	if start < 0 || start >= len(trimmed) || trimmed[start] != '{' {
+		return -1
+	}
+
+	if !json.Valid([]byte(trimmed[start:])) {
+		return -1
+	}
+
+	return start
+}
+
+// FuzzyMatch tells whether all characters of the query appear in text in the
+// same order, ignoring case. the score is higher when they are close together
+// or start words.
+func FuzzyMatch(text, query string) (int, bool) {
+	runes := []rune(strings.ToLower(text))
+
+	score, last, i := 0, -1, 0
+	for _, q := range strings.ToLower(query) {
+		for i < len(runes) && runes[i] != q {
+			i++
+		}
+		if i == len(runes) {
+			return 0, false
+		}
+
+		score++
+		if last >= 0 && i == last+1 {
+			score += 4
+		}
+		if i == 0 || strings.ContainsRune(" -_./:", runes[i-1]) {
+			score += 2
+		}
+		last = i
+		i++
+	}
+
+	return score, true
+}
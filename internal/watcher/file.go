@@ -0,0 +1,83 @@
+package watcher
+
+import (
+	"os"
+	"time"
+)
+
+// FileWatcher polls a single file and reports whenever its contents change
+type FileWatcher struct {
+	path     string
+	interval time.Duration
+	changes  chan struct{}
+	done     chan struct{}
+}
+
+// WatchFile starts watching the file at path, checking it every interval
+func WatchFile(path string, interval time.Duration) *FileWatcher {
+	w := &FileWatcher{
+		path:     path,
+		interval: interval,
+		changes:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Changes receives a value every time the file was modified. multiple
+// changes in quick succession are collapsed into one.
+func (w *FileWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching the file
+func (w *FileWatcher) Close() {
+	close(w.done)
+}
+
+func (w *FileWatcher) run() {
+	last := w.stat()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			current := w.stat()
+			if current == last {
+				continue
+			}
+			last = current
+
+			// editors often save in several steps, so skip the moments the file is gone
+			if current.missing {
+				continue
+			}
+
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	missing bool
+}
+
+func (w *FileWatcher) stat() fileState {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fileState{missing: true}
+	}
+
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,185 @@
+//go:build linux
+
+package watcher
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_CREATE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF
+
+// inotifyBackend watches directories with inotify. inotify isn't recursive,
+// so every directory gets its own watch and new directories are added as
+// they appear.
+type inotifyBackend struct {
+	fd      int
+	skipDir func(string) bool
+	notify  func(string)
+	mutex   sync.Mutex
+	watches map[int]string
+	done    chan struct{}
+}
+
+func newBackend(paths []string, skipDir func(string) bool, notify func(string)) (backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+
+	b := &inotifyBackend{
+		fd:      fd,
+		skipDir: skipDir,
+		notify:  notify,
+		watches: make(map[int]string),
+		done:    make(chan struct{}),
+	}
+
+	for _, p := range paths {
+		if err := b.addRecursive(p); err != nil {
+			_ = unix.Close(fd)
+			return nil, err
+		}
+	}
+
+	go b.run()
+
+	return b, nil
+}
+
+func (b *inotifyBackend) addRecursive(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// directories can disappear while we walk them
+			if p != root && os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to watch %s: %w", p, err)
+		}
+
+		if !d.IsDir() {
+			// a file passed in directly still needs a watch of its own
+			if p == root {
+				return b.add(p)
+			}
+			return nil
+		}
+
+		if p != root && b.skipDir(p) {
+			return filepath.SkipDir
+		}
+
+		return b.add(p)
+	})
+}
+
+func (b *inotifyBackend) add(p string) error {
+	wd, err := unix.InotifyAddWatch(b.fd, p, inotifyMask)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", p, err)
+	}
+
+	b.mutex.Lock()
+	b.watches[wd] = p
+	b.mutex.Unlock()
+
+	return nil
+}
+
+func (b *inotifyBackend) run() {
+	defer func() {
+		if err := unix.Close(b.fd); err != nil {
+			log.Printf("error closing inotify: %v", err)
+		}
+	}()
+
+	buffer := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(b.fd), Events: unix.POLLIN}}
+
+	for {
+		select {
+		case <-b.done:
+			return
+		default:
+		}
+
+		// poll with a timeout so that close can stop this loop
+		n, err := unix.Poll(fds, 250)
+		if err != nil && err != unix.EINTR {
+			log.Printf("error polling inotify: %v", err)
+			return
+		}
+		if n <= 0 {
+			continue
+		}
+
+		n, err = unix.Read(b.fd, buffer)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				continue
+			}
+			log.Printf("error reading inotify events: %v", err)
+			return
+		}
+
+		b.handle(buffer[:n])
+	}
+}
+
+func (b *inotifyBackend) handle(data []byte) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(data); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&data[offset]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameBytes := data[nameStart : nameStart+int(event.Len)]
+		offset = nameStart + int(event.Len)
+
+		name := string(nameBytes)
+		for len(name) > 0 && name[len(name)-1] == 0 {
+			name = name[:len(name)-1]
+		}
+
+		b.mutex.Lock()
+		dir, ok := b.watches[int(event.Wd)]
+		if event.Mask&unix.IN_IGNORED != 0 {
+			delete(b.watches, int(event.Wd))
+		}
+		b.mutex.Unlock()
+
+		if !ok {
+			continue
+		}
+
+		p := dir
+		if name != "" {
+			p = filepath.Join(dir, name)
+		}
+
+		if event.Mask&unix.IN_ISDIR != 0 {
+			if event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 && !b.skipDir(p) {
+				if err := b.addRecursive(p); err != nil {
+					log.Printf("error watching new directory: %v", err)
+				}
+			}
+			continue
+		}
+
+		if event.Mask&(unix.IN_IGNORED|unix.IN_DELETE_SELF) != 0 {
+			continue
+		}
+
+		b.notify(p)
+	}
+}
+
+// close stops the event loop, which closes the inotify descriptor on its way out
+func (b *inotifyBackend) close() {
+	close(b.done)
+}
@@ -0,0 +1,83 @@
+//go:build !linux
+
+package watcher
+
+import (
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// pollBackend is used where inotify isn't available and compares the
+// modification times of all files every second
+type pollBackend struct {
+	paths   []string
+	skipDir func(string) bool
+	notify  func(string)
+	done    chan struct{}
+}
+
+func newBackend(paths []string, skipDir func(string) bool, notify func(string)) (backend, error) {
+	b := &pollBackend{
+		paths:   paths,
+		skipDir: skipDir,
+		notify:  notify,
+		done:    make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b, nil
+}
+
+func (b *pollBackend) run() {
+	previous := b.scan()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			current := b.scan()
+			for p, modTime := range current {
+				if previous[p] != modTime {
+					b.notify(p)
+				}
+			}
+			for p := range previous {
+				if _, ok := current[p]; !ok {
+					b.notify(p)
+				}
+			}
+			previous = current
+		}
+	}
+}
+
+func (b *pollBackend) scan() map[string]time.Time {
+	files := make(map[string]time.Time)
+	for _, root := range b.paths {
+		_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if p != root && b.skipDir(p) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				files[p] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+func (b *pollBackend) close() {
+	close(b.done)
+}
@@ -0,0 +1,201 @@
+package watcher
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ignoredDirs are never descended into, they are huge and never interesting
+var ignoredDirs = map[string]bool{
+	".git":         true,
+	".ren":         true,
+	"node_modules": true,
+}
+
+// Options configures which files a Watcher reports changes for
+type Options struct {
+	// Dir is the directory paths are reported and matched relative to
+	Dir string
+	// Paths are the files and directories to watch, recursively
+	Paths []string
+	// Include limits the reported files to the ones matching these globs
+	Include []string
+	// Exclude drops the files matching these globs
+	Exclude []string
+	// Debounce is how long it should be quiet before a change is reported
+	Debounce time.Duration
+}
+
+// Event describes a batch of file changes
+type Event struct {
+	// Path is the last changed file, relative to Options.Dir
+	Path string
+	// Count is the number of distinct files that changed
+	Count int
+}
+
+// Watcher reports debounced changes to files in a set of directories
+type Watcher struct {
+	options Options
+	backend backend
+	changed chan string
+	events  chan Event
+	done    chan struct{}
+}
+
+// backend is implemented per platform. it's created with a notify func that
+// it calls with the absolute path of every file that changed.
+type backend interface {
+	close()
+}
+
+// New starts watching the configured paths
+func New(options Options) (*Watcher, error) {
+	w := &Watcher{
+		options: options,
+		changed: make(chan string, 64),
+		events:  make(chan Event, 1),
+		done:    make(chan struct{}),
+	}
+
+	b, err := newBackend(options.Paths, w.skipDir, w.notify)
+	if err != nil {
+		return nil, err
+	}
+	w.backend = b
+
+	go w.debounce()
+
+	return w, nil
+}
+
+// Events receives the debounced changes
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Close stops watching, after which Events is closed
+func (w *Watcher) Close() {
+	w.backend.close()
+	close(w.done)
+}
+
+func (w *Watcher) notify(abs string) {
+	rel := w.relative(abs)
+	if !w.matches(rel) {
+		return
+	}
+
+	select {
+	case w.changed <- rel:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) debounce() {
+	defer close(w.events)
+
+	var (
+		timer   <-chan time.Time
+		pending Event
+		seen    = make(map[string]bool)
+	)
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case rel := <-w.changed:
+			pending.Path = rel
+			if !seen[rel] {
+				seen[rel] = true
+				pending.Count++
+			}
+			timer = time.After(w.options.Debounce)
+		case <-timer:
+			select {
+			case w.events <- pending:
+			case <-w.done:
+				return
+			}
+			pending = Event{}
+			seen = make(map[string]bool)
+			timer = nil
+		}
+	}
+}
+
+func (w *Watcher) relative(abs string) string {
+	rel, err := filepath.Rel(w.options.Dir, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (w *Watcher) skipDir(abs string) bool {
+	if ignoredDirs[filepath.Base(abs)] {
+		return true
+	}
+
+	rel := w.relative(abs)
+	return rel != "." && matchAny(w.options.Exclude, rel)
+}
+
+func (w *Watcher) matches(rel string) bool {
+	if matchAny(w.options.Exclude, rel) {
+		return false
+	}
+
+	return len(w.options.Include) == 0 || matchAny(w.options.Include, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether the slash separated path matches the glob. patterns
+// without a slash match against the file name only, like in a .gitignore,
+// and ** matches any number of directories.
+func match(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(parts) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+
+		pattern, parts = pattern[1:], parts[1:]
+	}
+
+	return len(parts) == 0
+}
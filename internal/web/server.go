@@ -0,0 +1,249 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/thejawker/rennen/internal/control"
+)
+
+//go:embed dashboard.html
+var dashboard embed.FS
+
+// pollInterval is how often streams check for new output
+const pollInterval = 250 * time.Millisecond
+
+// Server serves the HTTP API and the dashboard. it answers everything with
+// the same handler as the control socket, so both see the same processes.
+type Server struct {
+	handler control.Handler
+	server  *http.Server
+	// host is the host the server was asked to listen on
+	host string
+}
+
+// Listen starts serving on addr, like "127.0.0.1:7777". an addr without a
+// host, like ":7777", only listens on 127.0.0.1.
+func Listen(addr string, handler control.Handler) (*Server, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+		addr = net.JoinHostPort(host, port)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &Server{handler: handler, host: host}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.serveDashboard)
+	mux.HandleFunc("GET /api/status", s.serveStatus(""))
+	mux.HandleFunc("GET /api/processes", s.serveStatus("process"))
+	mux.HandleFunc("GET /api/commands", s.serveStatus("command"))
+	mux.HandleFunc("GET /api/processes/{name}/output", s.serveOutput)
+	mux.HandleFunc("GET /api/processes/{name}/stream", s.serveStream)
+	mux.HandleFunc("POST /api/processes/{name}/{action}", s.serveAction)
+	mux.HandleFunc("POST /api/commands/{name}/trigger", s.serveTrigger)
+
+	s.server = &http.Server{Handler: s.guard(mux), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Error serving http: %v\n", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// guard turns away requests that other sites make from the browser. the
+// Host has to be the one ren listens on, so a DNS rebinding attack can't
+// reach it, and an Origin has to be the dashboard itself.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeJSON(w, http.StatusForbidden, errorBody{Error: "unknown host " + r.Host})
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				writeJSON(w, http.StatusForbidden, errorBody{Error: "cross-origin requests aren't allowed"})
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host of a request points at this server.
+// names other than localhost and the one ren listens on could be rebound to
+// anything, addresses can't.
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+
+	return host == "localhost" || host == s.host || net.ParseIP(host) != nil
+}
+
+// Close stops the server, cutting off open streams
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func (s *Server) serveDashboard(w http.ResponseWriter, r *http.Request) {
+	http.ServeFileFS(w, r, dashboard, "dashboard.html")
+}
+
+// serveStatus lists the status of the processes, commands or both
+func (s *Server) serveStatus(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.handler("status", control.Params{})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		statuses, _ := result.([]control.Status)
+		filtered := []control.Status{}
+		for _, status := range statuses {
+			if kind == "" || status.Kind == kind {
+				filtered = append(filtered, status)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, filtered)
+	}
+}
+
+func (s *Server) serveAction(w http.ResponseWriter, r *http.Request) {
+	switch action := r.PathValue("action"); action {
+	case "start", "stop", "restart", "clear":
+		s.call(w, action, control.Params{Name: r.PathValue("name")})
+	default:
+		writeJSON(w, http.StatusNotFound, errorBody{Error: "unknown action " + action})
+	}
+}
+
+func (s *Server) serveTrigger(w http.ResponseWriter, r *http.Request) {
+	s.call(w, "trigger", control.Params{Name: r.PathValue("name")})
+}
+
+// serveOutput returns the most recent lines, or the ones after ?after=seq
+func (s *Server) serveOutput(w http.ResponseWriter, r *http.Request) {
+	params := control.Params{Name: r.PathValue("name")}
+	params.Lines, _ = strconv.Atoi(r.URL.Query().Get("lines"))
+	params.After, _ = strconv.ParseUint(r.URL.Query().Get("after"), 10, 64)
+
+	s.call(w, "tail", params)
+}
+
+// serveStream sends the output of a process as server-sent events, starting
+// with the most recent lines
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, errorBody{Error: "streaming isn't supported"})
+		return
+	}
+
+	params := control.Params{Name: r.PathValue("name")}
+	params.Lines, _ = strconv.Atoi(r.URL.Query().Get("lines"))
+
+	// a reconnecting EventSource tells where it left off
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		params.After, _ = strconv.ParseUint(id, 10, 64)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.handler("tail", params)
+		if err != nil {
+			data, _ := json.Marshal(errorBody{Error: err.Error()})
+			fmt.Fprintf(w, "event: failed\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+		}
+
+		lines, _ := result.([]control.Line)
+		for _, line := range lines {
+			data, _ := json.Marshal(line)
+			fmt.Fprintf(w, "id: %d\ndata: %s\n\n", line.Seq, data)
+			params.After = line.Seq
+		}
+		if len(lines) > 0 {
+			flusher.Flush()
+		}
+
+		// after the first batch, everything that comes in
+		params.Lines = 10000
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) call(w http.ResponseWriter, method string, params control.Params) {
+	result, err := s.handler(method, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	// what's left is an action that couldn't be done, like starting a
+	// process that is already running
+	status := http.StatusConflict
+	switch {
+	case errors.Is(err, control.ErrInvalidParams):
+		status = http.StatusNotFound
+	case errors.Is(err, control.ErrMethodNotFound):
+		status = http.StatusNotFound
+	}
+
+	writeJSON(w, status, errorBody{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		log.Printf("Error writing http response: %v\n", err)
+	}
+}
@@ -0,0 +1,126 @@
+package wizard
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thejawker/rennen/internal/detect"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).PaddingBottom(1)
+	cursorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff00ff"))
+	processStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#15803d"))
+	commandStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#1d4ed8", Dark: "#8394a7"})
+	skippedStyle  = lipgloss.NewStyle().Faint(true)
+	sourceStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#a7a7a7", Dark: "#8a8a8a"})
+	hintStyle     = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#a7a7a7", Dark: "#8a8a8a"}).PaddingTop(1)
+	kindLabels    = map[Choice]string{ChoiceProcess: "process", ChoiceCommand: "command", ChoiceSkip: "skip"}
+	choiceStyles  = map[Choice]lipgloss.Style{ChoiceProcess: processStyle, ChoiceCommand: commandStyle, ChoiceSkip: skippedStyle}
+	shortnameSize = 24
+)
+
+// Choice is what the user wants an entry to become
+type Choice int
+
+const (
+	ChoiceSkip Choice = iota
+	ChoiceProcess
+	ChoiceCommand
+)
+
+// Item is a detected entry together with the choice made for it
+type Item struct {
+	Entry  detect.Entry
+	Choice Choice
+}
+
+// Checklist lets the user pick which detected entries become processes and
+// which become commands
+type Checklist struct {
+	Items     []Item
+	Cancelled bool
+	cursor    int
+}
+
+// NewChecklist creates a checklist with the suggested choice preselected
+func NewChecklist(entries []detect.Entry) *Checklist {
+	items := make([]Item, len(entries))
+	for i, entry := range entries {
+		items[i] = Item{Entry: entry, Choice: Suggested(entry)}
+	}
+
+	return &Checklist{Items: items}
+}
+
+// Suggested is the choice for an entry when nobody is around to make one
+func Suggested(entry detect.Entry) Choice {
+	if entry.Kind == detect.KindProcess {
+		return ChoiceProcess
+	}
+	return ChoiceCommand
+}
+
+func (c *Checklist) Init() tea.Cmd {
+	return nil
+}
+
+func (c *Checklist) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return c, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "q", "esc":
+		c.Cancelled = true
+		return c, tea.Quit
+	case "enter":
+		return c, tea.Quit
+	case "up", "k":
+		c.cursor = (c.cursor - 1 + len(c.Items)) % len(c.Items)
+	case "down", "j":
+		c.cursor = (c.cursor + 1) % len(c.Items)
+	case " ", "tab":
+		item := &c.Items[c.cursor]
+		item.Choice = (item.Choice + 1) % 3
+	case "p":
+		c.Items[c.cursor].Choice = ChoiceProcess
+	case "c":
+		c.Items[c.cursor].Choice = ChoiceCommand
+	case "s", "x":
+		c.Items[c.cursor].Choice = ChoiceSkip
+	}
+
+	return c, nil
+}
+
+func (c *Checklist) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("found these in your project, pick what ren should run:"))
+	b.WriteString("\n")
+
+	for i, item := range c.Items {
+		prefix := "  "
+		if i == c.cursor {
+			prefix = cursorStyle.Render("› ")
+		}
+
+		style := choiceStyles[item.Choice]
+		label := fmt.Sprintf("[%-7s]", kindLabels[item.Choice])
+		name := fmt.Sprintf("%-*s", shortnameSize, item.Entry.Shortname)
+
+		b.WriteString(prefix)
+		b.WriteString(style.Render(label + " " + name))
+		b.WriteString(sourceStyle.Render(fmt.Sprintf(" $ %s  (%s)", item.Entry.Command, item.Entry.Source)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(hintStyle.Render("↑/↓ select, space cycle, (p)rocess, (c)ommand, (s)kip, ↵ save, (q)uit"))
+	b.WriteString("\n")
+
+	return b.String()
+}